@@ -29,6 +29,16 @@ func (a *Account) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Account{}
+
+func (a Account) MarshalJSON() ([]byte, error) {
+	if len(a.rawJSON) > 0 {
+		return a.rawJSON, nil
+	}
+	type Alias Account
+	return json.Marshal(Alias(a))
+}
+
 // GetCurrentAccount returns the authenticated user or organization.
 func (r *Client) GetCurrentAccount(ctx context.Context) (*Account, error) {
 	response := &Account{}
@@ -38,3 +48,14 @@ func (r *Client) GetCurrentAccount(ctx context.Context) (*Account, error) {
 	}
 	return response, nil
 }
+
+// GetAccount returns the user or organization with the specified username.
+func (r *Client) GetAccount(ctx context.Context, username string) (*Account, error) {
+	response := &Account{}
+	path := fmt.Sprintf("/account/%s", username)
+	err := r.fetch(ctx, http.MethodGet, path, nil, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	return response, nil
+}
@@ -3,6 +3,7 @@ package replicate
 import (
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -15,11 +16,15 @@ type Backoff interface {
 type ConstantBackoff struct {
 	Base   time.Duration
 	Jitter time.Duration
+
+	// Rand is the source of jitter.
+	// If it's nil, the global source is used.
+	Rand *rand.Rand
 }
 
 // NextDelay returns the next delay.
 func (b *ConstantBackoff) NextDelay(_ int) time.Duration {
-	jitter := time.Duration(rand.Float64() * float64(b.Jitter)) //#nosec G404
+	jitter := time.Duration(randFloat64(b.Rand) * float64(b.Jitter))
 	return b.Base + jitter
 }
 
@@ -28,10 +33,69 @@ type ExponentialBackoff struct {
 	Base       time.Duration
 	Multiplier float64
 	Jitter     time.Duration
+
+	// Max caps the delay, including jitter, so that a high number of retries
+	// doesn't lead to very long waits.
+	// If it's zero, the delay isn't capped.
+	Max time.Duration
+
+	// Rand is the source of jitter.
+	// If it's nil, the global source is used.
+	// Giving each backoff its own source avoids contention on the global source's lock
+	// when many requests are retried at once.
+	Rand *rand.Rand
 }
 
 // NextDelay returns the next delay.
 func (b *ExponentialBackoff) NextDelay(retries int) time.Duration {
-	jitter := time.Duration(rand.Float64() * float64(b.Jitter)) //#nosec G404
-	return time.Duration(float64(b.Base)*math.Pow(b.Multiplier, float64(retries))) + jitter
+	jitter := randFloat64(b.Rand) * float64(b.Jitter)
+	delay := float64(b.Base)*math.Pow(b.Multiplier, float64(retries)) + jitter
+	if b.Max > 0 {
+		delay = math.Min(delay, float64(b.Max))
+	}
+	return time.Duration(delay)
+}
+
+// FullJitterBackoff is a backoff strategy that returns a random delay
+// between zero and an exponentially increasing limit, capped at Max.
+//
+// Unlike ExponentialBackoff, which adds a small jitter to the same delay,
+// it spreads retries over the whole interval.
+// Prefer it when many clients may fail at once, such as during an outage or rate limiting,
+// so that their retries don't arrive together.
+type FullJitterBackoff struct {
+	Base       time.Duration
+	Multiplier float64
+	Max        time.Duration
+
+	// Rand is the source of random delays.
+	// If it's nil, the global source is used.
+	// Set it to make delays deterministic in tests.
+	Rand *rand.Rand
+}
+
+// NextDelay returns the next delay.
+func (b *FullJitterBackoff) NextDelay(retries int) time.Duration {
+	limit := float64(b.Base) * math.Pow(b.Multiplier, float64(retries))
+	if b.Max > 0 {
+		limit = math.Min(limit, float64(b.Max))
+	}
+	return time.Duration(randFloat64(b.Rand) * limit)
+}
+
+// randLocks holds a lock for each rand.Rand used by a backoff, since a rand.Rand
+// isn't safe for concurrent use. Keeping the locks out of the backoff structs
+// means they're safe to copy, and copies, or backoffs that share a source, share its lock.
+var randLocks sync.Map // map[*rand.Rand]*sync.Mutex
+
+// randFloat64 returns a random number in [0.0, 1.0) from r, or from the global source if r is nil.
+func randFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64() //#nosec G404
+	}
+
+	mu, _ := randLocks.LoadOrStore(r, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+	return r.Float64() //#nosec G404
 }
@@ -0,0 +1,117 @@
+package replicate_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestFullJitterBackoff(t *testing.T) {
+	backoff := &replicate.FullJitterBackoff{
+		Base:       100 * time.Millisecond,
+		Multiplier: 2,
+		Max:        time.Second,
+		Rand:       rand.New(rand.NewSource(1)),
+	}
+
+	// A backoff with the same seed returns the same delays
+	expected := rand.New(rand.NewSource(1))
+	for retries, limit := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		delay := backoff.NextDelay(retries)
+		assert.Equal(t, time.Duration(expected.Float64()*float64(limit)), delay)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, limit)
+	}
+}
+
+func TestBackoffWithRand(t *testing.T) {
+	constant := &replicate.ConstantBackoff{
+		Base:   time.Second,
+		Jitter: 100 * time.Millisecond,
+		Rand:   rand.New(rand.NewSource(1)),
+	}
+	exponential := &replicate.ExponentialBackoff{
+		Base:       time.Second,
+		Multiplier: 2,
+		Jitter:     100 * time.Millisecond,
+		Rand:       rand.New(rand.NewSource(1)),
+	}
+
+	expected := rand.New(rand.NewSource(1))
+	for retries := 0; retries < 3; retries++ {
+		jitter := time.Duration(expected.Float64() * float64(100*time.Millisecond))
+		assert.Equal(t, time.Second+jitter, constant.NextDelay(retries))
+	}
+
+	expected = rand.New(rand.NewSource(1))
+	for retries, base := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		jitter := time.Duration(expected.Float64() * float64(100*time.Millisecond))
+		assert.Equal(t, base+jitter, exponential.NextDelay(retries))
+	}
+
+	// Without a source, the jitter is still within bounds
+	delay := (&replicate.ConstantBackoff{Base: time.Second, Jitter: 100 * time.Millisecond}).NextDelay(0)
+	assert.GreaterOrEqual(t, delay, time.Second)
+	assert.Less(t, delay, time.Second+100*time.Millisecond)
+}
+
+func TestExponentialBackoffMax(t *testing.T) {
+	backoff := &replicate.ExponentialBackoff{
+		Base:       100 * time.Millisecond,
+		Multiplier: 2,
+		Jitter:     50 * time.Millisecond,
+		Max:        time.Second,
+		Rand:       rand.New(rand.NewSource(1)),
+	}
+
+	// Delays below the cap aren't affected
+	delay := backoff.NextDelay(0)
+	assert.GreaterOrEqual(t, delay, 100*time.Millisecond)
+	assert.Less(t, delay, 150*time.Millisecond)
+
+	for _, retries := range []int{4, 10, 100, 10000} {
+		assert.Equal(t, time.Second, backoff.NextDelay(retries), "retries: %d", retries)
+	}
+
+	// Without a cap, the delay keeps growing
+	backoff.Max = 0
+	assert.Greater(t, backoff.NextDelay(10), time.Minute)
+}
+
+func TestBackoffCopiesShareRand(t *testing.T) {
+	backoff := replicate.ExponentialBackoff{
+		Base:       time.Second,
+		Multiplier: 2,
+		Jitter:     100 * time.Millisecond,
+		Rand:       rand.New(rand.NewSource(1)),
+	}
+
+	// Copies use the same source, so they must also use the same lock
+	copied := backoff
+
+	var wg sync.WaitGroup
+	for _, b := range []*replicate.ExponentialBackoff{&backoff, &copied} {
+		wg.Add(1)
+		go func(b *replicate.ExponentialBackoff) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				delay := b.NextDelay(0)
+				assert.GreaterOrEqual(t, delay, time.Second)
+				assert.Less(t, delay, time.Second+100*time.Millisecond)
+			}
+		}(b)
+	}
+	wg.Wait()
+}
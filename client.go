@@ -8,47 +8,88 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"strconv"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 var (
 	envAuthToken = "REPLICATE_API_TOKEN"
 
-	defaultUserAgent = "replicate/go" // TODO: embed version information
+	defaultUserAgent = userAgent()
 	defaultBaseURL   = "https://api.replicate.com/v1"
 
+	defaultAuthScheme = "Bearer"
+
 	defaultMaxRetries = 5
 	defaultBackoff    = &ExponentialBackoff{
 		Multiplier: 2,
 		Base:       500 * time.Millisecond,
 		Jitter:     50 * time.Millisecond,
+		Max:        30 * time.Second,
 	}
 
 	ErrNoAuth       = errors.New(`no auth token or token source provided -- perhaps you forgot to pass replicate.WithToken("...")`)
 	ErrEnvVarNotSet = fmt.Errorf("%s environment variable not set", envAuthToken)
 	ErrEnvVarEmpty  = fmt.Errorf("%s environment variable is empty", envAuthToken)
+
+	ErrResponseTooLarge = errors.New("response body exceeds maximum size")
 )
 
 // Client is a client for the Replicate API.
 type Client struct {
 	options *clientOptions
 	c       *http.Client
+
+	// closed is canceled by Close to stop streams started by the client.
+	closed context.Context
+	close  context.CancelFunc
+
+	// waits is canceled by Close to stop waits in progress,
+	// and replaced so that waits started after Close still poll.
+	mu          sync.Mutex
+	waits       context.Context
+	cancelWaits context.CancelFunc
 }
 
 type retryPolicy struct {
 	maxRetries int
 	backoff    Backoff
+
+	// statusCodes and methods are retried in addition to the defaults.
+	statusCodes []int
+	methods     []string
 }
 
 type clientOptions struct {
 	auth        string
+	authScheme  string
 	baseURL     string
 	httpClient  *http.Client
 	retryPolicy *retryPolicy
+
+	// ownsHTTPClient is true if httpClient was created by an option,
+	// rather than passed in by the caller or shared, like http.DefaultClient.
+	ownsHTTPClient bool
+
 	userAgent   *string
+	headers     http.Header
+	rateLimiter *rate.Limiter
+	logger      Logger
+
+	searchMethod   string
+	defaultWebhook *Webhook
+
+	maxResponseBodySize int64
+
+	streamEventHandler func(SSEEvent)
+
+	responseCallback func(*http.Response)
 }
 
 // ClientOption is a function that modifies an options struct.
@@ -58,13 +99,15 @@ type ClientOption func(*clientOptions) error
 func NewClient(opts ...ClientOption) (*Client, error) {
 	c := &Client{
 		options: &clientOptions{
-			userAgent: &defaultUserAgent,
-			baseURL:   defaultBaseURL,
+			userAgent:  &defaultUserAgent,
+			authScheme: defaultAuthScheme,
+			baseURL:    defaultBaseURL,
 			retryPolicy: &retryPolicy{
 				maxRetries: defaultMaxRetries,
 				backoff:    defaultBackoff,
 			},
 			httpClient: http.DefaultClient,
+			logger:     noopLogger{},
 		},
 	}
 
@@ -88,10 +131,65 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	}
 
 	c.c = c.options.httpClient
+	c.closed, c.close = context.WithCancel(context.Background())
+	c.waits, c.cancelWaits = context.WithCancel(context.Background())
 
 	return c, nil
 }
 
+// Close releases resources held by the client, for use during graceful shutdown.
+//
+// It stops streams and waits started by the client that are still in progress,
+// as if their contexts were canceled.
+// If the client created its own HTTP client, such as with WithProxyURL,
+// it also closes that client's idle keep-alive connections.
+// HTTP clients passed with WithHTTPClient, and http.DefaultClient, are left open,
+// since they may be shared with other code.
+//
+// Other requests and waits can still be made after Close,
+// but streams started after Close stop immediately.
+// Close is safe to call more than once.
+func (r *Client) Close() error {
+	r.close()
+
+	r.mu.Lock()
+	r.cancelWaits()
+	r.waits, r.cancelWaits = context.WithCancel(context.Background())
+	r.mu.Unlock()
+
+	if r.options.ownsHTTPClient {
+		r.c.CloseIdleConnections()
+	}
+	return nil
+}
+
+// streamContext returns a context that's canceled when ctx is done or the client is closed.
+// The returned function must be called when the context is no longer needed.
+func (r *Client) streamContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(r.closed, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// waitContext returns a context that's canceled when ctx is done
+// or the client is closed while the wait is in progress.
+// The returned function must be called when the context is no longer needed.
+func (r *Client) waitContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	r.mu.Lock()
+	waits := r.waits
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(waits, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
 // WithToken sets the auth token used by the client.
 func WithToken(token string) ClientOption {
 	return func(o *clientOptions) error {
@@ -100,6 +198,21 @@ func WithToken(token string) ClientOption {
 	}
 }
 
+// WithAuthScheme sets the scheme of the Authorization header sent with the token,
+// either "Bearer" or "Token". The default is "Bearer".
+// Some proxies and self-hosted gateways only accept one of them.
+func WithAuthScheme(scheme string) ClientOption {
+	return func(o *clientOptions) error {
+		switch scheme {
+		case "Bearer", "Token":
+			o.authScheme = scheme
+			return nil
+		default:
+			return fmt.Errorf("unsupported auth scheme %q, must be \"Bearer\" or \"Token\"", scheme)
+		}
+	}
+}
+
 // WithTokenFromEnv configures the client to use the auth token provided in the
 // REPLICATE_API_TOKEN environment variable.
 func WithTokenFromEnv() ClientOption {
@@ -116,7 +229,9 @@ func WithTokenFromEnv() ClientOption {
 	}
 }
 
-// WithUserAgent sets the User-Agent header on requests made by the client.
+// WithUserAgent sets the User-Agent header on requests made by the client,
+// replacing the default one, which identifies the client version.
+// Use WithUserAgentSuffix to keep it.
 func WithUserAgent(userAgent string) ClientOption {
 	return func(o *clientOptions) error {
 		o.userAgent = &userAgent
@@ -124,9 +239,151 @@ func WithUserAgent(userAgent string) ClientOption {
 	}
 }
 
+// WithUserAgentSuffix appends a product to the User-Agent header on requests made by the client,
+// such as "my-app/1.0", so that requests identify both the application and the client version.
+func WithUserAgentSuffix(product string) ClientOption {
+	return func(o *clientOptions) error {
+		userAgent := product
+		if o.userAgent != nil && *o.userAgent != "" {
+			userAgent = *o.userAgent + " " + product
+		}
+		o.userAgent = &userAgent
+		return nil
+	}
+}
+
+// WithDefaultHeaders sets headers on every request made by the client,
+// in addition to the ones the client sets itself.
+// The Authorization, Content-Type, and User-Agent headers set by the client take precedence.
+func WithDefaultHeaders(headers map[string]string) ClientOption {
+	return func(o *clientOptions) error {
+		if o.headers == nil {
+			o.headers = make(http.Header, len(headers))
+		}
+		for key, value := range headers {
+			o.headers.Set(key, value)
+		}
+		return nil
+	}
+}
+
+// WithRateLimit limits the rate of requests made by the client,
+// allowing bursts of up to burst requests.
+// Requests wait until they're allowed, or their context is done,
+// which smooths bursty traffic instead of relying on retries after rate limit errors.
+// Retries count against the limit.
+func WithRateLimit(requestsPerSecond float64, burst int) ClientOption {
+	return func(o *clientOptions) error {
+		if requestsPerSecond <= 0 {
+			return fmt.Errorf("requests per second must be positive: %v", requestsPerSecond)
+		}
+		if burst < 1 {
+			return fmt.Errorf("burst must be at least 1: %d", burst)
+		}
+		o.rateLimiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+		return nil
+	}
+}
+
+// WithMaxResponseBodySize limits the size of the response bodies the client reads into memory.
+// Requests whose response is larger fail with ErrResponseTooLarge,
+// which bounds memory use if a proxy or server misbehaves.
+// By default, response bodies aren't limited.
+// Streams and downloaded files aren't read into memory, so they aren't limited.
+func WithMaxResponseBodySize(size int64) ClientOption {
+	return func(o *clientOptions) error {
+		if size <= 0 {
+			return fmt.Errorf("maximum response body size must be positive: %d", size)
+		}
+		o.maxResponseBodySize = size
+		return nil
+	}
+}
+
+// WithStreamEventHandler sets a function that's called with the stream events
+// that StreamPredictionText and StreamPredictionFiles don't consume,
+// such as logs and event types added to the API after this client was built.
+// Without a handler, those events are skipped.
+//
+// StreamPredictionEvents delivers every event on its channel, with its type and data as received,
+// so it doesn't need a handler.
+func WithStreamEventHandler(handler func(event SSEEvent)) ClientOption {
+	return func(o *clientOptions) error {
+		o.streamEventHandler = handler
+		return nil
+	}
+}
+
+// WithResponseCallback sets a function that's called with every response the client receives,
+// including error responses and those that are retried, before the body is read.
+// It can be used to log headers such as rate limits and request IDs.
+// The function must not read or close the response body.
+func WithResponseCallback(callback func(*http.Response)) ClientOption {
+	return func(o *clientOptions) error {
+		o.responseCallback = callback
+		return nil
+	}
+}
+
+// WithSearchMethod sets the HTTP method used by SearchModels.
+// The method must be "QUERY", the default, or "POST".
+// Use POST if the client is behind a proxy that rejects non-standard methods.
+func WithSearchMethod(method string) ClientOption {
+	return func(o *clientOptions) error {
+		switch method {
+		case searchMethodQuery, http.MethodPost:
+			o.searchMethod = method
+			return nil
+		default:
+			return fmt.Errorf("unsupported search method: %q", method)
+		}
+	}
+}
+
+// WithLogger sets the logger that receives messages about requests,
+// retries, and stream reconnects made by the client.
+// By default, messages are discarded.
+func WithLogger(logger Logger) ClientOption {
+	return func(o *clientOptions) error {
+		if logger == nil {
+			logger = noopLogger{}
+		}
+		o.logger = logger
+		return nil
+	}
+}
+
+// WithDefaultWebhook sets the webhook used when a prediction or training
+// is created with a nil webhook.
+// A webhook passed when creating a prediction or training overrides it.
+func WithDefaultWebhook(webhook *Webhook) ClientOption {
+	return func(o *clientOptions) error {
+		if webhook == nil {
+			o.defaultWebhook = nil
+			return nil
+		}
+		if err := webhook.Validate(); err != nil {
+			return fmt.Errorf("invalid default webhook: %w", err)
+		}
+		o.defaultWebhook = &Webhook{
+			URL:    webhook.URL,
+			Events: slices.Clone(webhook.Events),
+		}
+		return nil
+	}
+}
+
 // WithBaseURL sets the base URL for the client.
+// The URL must be absolute, with a scheme and host, such as "https://api.replicate.com/v1".
 func WithBaseURL(baseURL string) ClientOption {
 	return func(o *clientOptions) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid base URL %q: must have a scheme and host", baseURL)
+		}
 		o.baseURL = baseURL
 		return nil
 	}
@@ -136,6 +393,38 @@ func WithBaseURL(baseURL string) ClientOption {
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(o *clientOptions) error {
 		o.httpClient = httpClient
+		o.ownsHTTPClient = false
+		return nil
+	}
+}
+
+// WithProxyURL configures the client to send requests through the given proxy.
+//
+// The proxy is set on a copy of the transport of the current HTTP client,
+// so retries, headers, and other transport settings are preserved.
+// Options are applied in order, so a later WithHTTPClient replaces the proxied client,
+// and a later WithProxyURL applies to the client set by an earlier WithHTTPClient.
+func WithProxyURL(proxyURL *url.URL) ClientOption {
+	return func(o *clientOptions) error {
+		if proxyURL == nil {
+			return errors.New("proxy URL must not be nil")
+		}
+
+		var transport *http.Transport
+		switch t := o.httpClient.Transport.(type) {
+		case nil:
+			transport = http.DefaultTransport.(*http.Transport).Clone()
+		case *http.Transport:
+			transport = t.Clone()
+		default:
+			return fmt.Errorf("cannot set proxy on HTTP client transport of type %T", t)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+
+		httpClient := *o.httpClient
+		httpClient.Transport = transport
+		o.httpClient = &httpClient
+		o.ownsHTTPClient = true
 		return nil
 	}
 }
@@ -143,23 +432,61 @@ func WithHTTPClient(httpClient *http.Client) ClientOption {
 // WithRetryPolicy sets the retry policy used by the client.
 func WithRetryPolicy(maxRetries int, backoff Backoff) ClientOption {
 	return func(o *clientOptions) error {
-		o.retryPolicy = &retryPolicy{
-			maxRetries: maxRetries,
-			backoff:    backoff,
+		policy := *o.retryPolicy
+		policy.maxRetries = maxRetries
+		policy.backoff = backoff
+		o.retryPolicy = &policy
+		return nil
+	}
+}
+
+// WithRetryableStatusCodes adds status codes that are retried,
+// in addition to 429 and 5xx.
+// Like 5xx, they're only retried for GET requests
+// and requests with methods added by WithRetryMethods.
+func WithRetryableStatusCodes(codes ...int) ClientOption {
+	return func(o *clientOptions) error {
+		for _, code := range codes {
+			if code < 100 || code > 599 {
+				return fmt.Errorf("invalid HTTP status code: %d", code)
+			}
+		}
+		policy := *o.retryPolicy
+		policy.statusCodes = append(slices.Clone(policy.statusCodes), codes...)
+		o.retryPolicy = &policy
+		return nil
+	}
+}
+
+// WithRetryMethods adds HTTP methods whose requests are retried
+// like GET requests, when the response status is 5xx
+// or one added by WithRetryableStatusCodes.
+// Only add methods whose requests are safe to send more than once.
+func WithRetryMethods(methods ...string) ClientOption {
+	return func(o *clientOptions) error {
+		policy := *o.retryPolicy
+		policy.methods = slices.Clone(policy.methods)
+		for _, method := range methods {
+			policy.methods = append(policy.methods, strings.ToUpper(method))
 		}
+		o.retryPolicy = &policy
 		return nil
 	}
 }
 
 func (r *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
-	url := constructURL(r.options.baseURL, path)
-	request, err := http.NewRequestWithContext(ctx, method, url, body)
+	requestURL := constructURL(r.options.baseURL, path)
+	request, err := http.NewRequestWithContext(ctx, method, requestURL, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	for key, values := range r.options.headers {
+		request.Header[key] = append([]string(nil), values...)
+	}
+
 	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", r.options.auth))
+	request.Header.Set("Authorization", r.authorization())
 	if r.options.userAgent != nil {
 		request.Header.Set("User-Agent", *r.options.userAgent)
 	}
@@ -167,48 +494,92 @@ func (r *Client) newRequest(ctx context.Context, method, path string, body io.Re
 	return request, nil
 }
 
-func (r *Client) do(request *http.Request, out interface{}) error {
+func (r *Client) do(request *http.Request, out interface{}) (err error) {
 	maxRetries := r.options.retryPolicy.maxRetries
 	backoff := r.options.retryPolicy.backoff
 
+	// Errors after a response is received are wrapped with the ID of the request
+	var requestID string
+	defer func() {
+		if err != nil && requestID != "" {
+			err = &RequestError{RequestID: requestID, Err: err}
+		}
+	}()
+
 	var apiError *APIError
 	attempts := 0
 	for ok := true; ok; ok = attempts < maxRetries {
+		if r.options.rateLimiter != nil {
+			if err := r.options.rateLimiter.Wait(request.Context()); err != nil {
+				return fmt.Errorf("failed to wait for rate limit: %w", err)
+			}
+		}
+
+		r.options.logger.Debugf("%s %s, attempt=%d", request.Method, request.URL, attempts+1)
+
 		response, err := r.c.Do(request)
 		if err != nil || response == nil {
+			r.options.logger.Warnf("%s %s failed: %v", request.Method, request.URL, err)
 			return fmt.Errorf("failed to make request: %w", err)
 		}
 		defer response.Body.Close()
 
-		responseBytes, err := io.ReadAll(response.Body)
+		requestID = requestIDFromHeader(response.Header)
+
+		if r.options.responseCallback != nil {
+			r.options.responseCallback(response)
+		}
+
+		responseBytes, err := r.readResponseBody(response)
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
 
 		if response.StatusCode < 200 || response.StatusCode >= 400 {
 			apiError = unmarshalAPIError(response, responseBytes)
-			if !r.shouldRetry(response, request.Method) {
+			// The loop makes at most maxRetries requests (at least one).
+			// After the last one, the error is returned right away,
+			// rather than after waiting for a retry that won't be made.
+			if !r.shouldRetry(response, request.Method) || attempts+1 >= maxRetries {
 				return apiError
 			}
 
-			delay := backoff.NextDelay(attempts)
-
-			retryAfter := response.Header.Get("Retry-After")
-			if retryAfter != "" {
-				if parsedDelay, parseErr := time.Parse(time.RFC1123, retryAfter); parseErr == nil {
-					delay = time.Until(parsedDelay)
-				} else if seconds, convErr := strconv.Atoi(retryAfter); convErr == nil {
-					delay = time.Duration(seconds) * time.Second
+			// The body was consumed by the previous attempt, so it must be
+			// rewound before retrying. Streamed bodies can't be rewound.
+			if request.Body != nil && request.Body != http.NoBody {
+				if request.GetBody == nil {
+					return apiError
 				}
+				body, err := request.GetBody()
+				if err != nil {
+					return apiError
+				}
+				request.Body = body
 			}
 
+			delay := backoff.NextDelay(attempts)
+			if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+
+			r.options.logger.Warnf("retrying %s %s after %d, delay=%s, attempt=%d", request.Method, request.URL, response.StatusCode, delay, attempts+2)
+
 			if delay > 0 {
 				time.Sleep(delay)
 			}
 
 			attempts++
 		} else {
-			if out != nil {
+			if s, ok := out.(statusCodeReceiver); ok {
+				s.setStatusCode(response.StatusCode)
+			}
+
+			if w, ok := out.(io.Writer); ok {
+				// Non-JSON responses are written as-is
+				if _, err := w.Write(responseBytes); err != nil {
+					return fmt.Errorf("failed to write response: %w", err)
+				}
+			} else if out != nil && !isEmptyResponse(response.StatusCode, responseBytes) {
 				if err := json.Unmarshal(responseBytes, &out); err != nil {
 					return fmt.Errorf("failed to unmarshal response: %w", err)
 				}
@@ -229,6 +600,89 @@ func (r *Client) do(request *http.Request, out interface{}) error {
 	return fmt.Errorf("request failed")
 }
 
+// isEmptyResponse returns true if a successful response has no body to unmarshal,
+// as with 204 No Content, or 202 Accepted without details of the accepted request.
+func isEmptyResponse(statusCode int, body []byte) bool {
+	switch statusCode {
+	case http.StatusNoContent:
+		return true
+	case http.StatusAccepted:
+		return len(bytes.TrimSpace(body)) == 0
+	default:
+		return false
+	}
+}
+
+// statusCodeReceiver is implemented by responses that need the status code,
+// as well as the body, of a successful response.
+type statusCodeReceiver interface {
+	setStatusCode(statusCode int)
+}
+
+// DeleteResult is the response to a request to delete a resource.
+type DeleteResult struct {
+	// StatusCode is the status code of the response.
+	// It's 202 Accepted if the resource is being deleted asynchronously,
+	// or 200 OK or 204 No Content if it was deleted.
+	StatusCode int
+
+	rawJSON json.RawMessage
+}
+
+// Accepted returns true if the API accepted the request
+// and will finish deleting the resource asynchronously.
+func (d *DeleteResult) Accepted() bool {
+	return d.StatusCode == http.StatusAccepted
+}
+
+// RawJSON returns the body of the response, or nil if it was empty or not JSON.
+// Asynchronous deletions may describe the deletion job in the body.
+func (d *DeleteResult) RawJSON() json.RawMessage {
+	return d.rawJSON
+}
+
+// deleteResultWriter receives the response to a delete request.
+// The body is written rather than unmarshaled,
+// so that a body that isn't JSON doesn't fail the request.
+type deleteResultWriter struct {
+	*DeleteResult
+}
+
+func (w deleteResultWriter) setStatusCode(statusCode int) {
+	w.StatusCode = statusCode
+}
+
+func (w deleteResultWriter) Write(p []byte) (int, error) {
+	if len(bytes.TrimSpace(p)) > 0 && json.Valid(p) {
+		w.rawJSON = append(w.rawJSON, p...)
+	}
+	return len(p), nil
+}
+
+// authorization returns the value of the Authorization header sent with requests.
+func (r *Client) authorization() string {
+	return fmt.Sprintf("%s %s", r.options.authScheme, r.options.auth)
+}
+
+// readResponseBody reads the body of a response,
+// up to the maximum size set by WithMaxResponseBodySize.
+func (r *Client) readResponseBody(response *http.Response) ([]byte, error) {
+	limit := r.options.maxResponseBodySize
+	if limit <= 0 {
+		return io.ReadAll(response.Body)
+	}
+
+	// Read one byte past the limit to tell a body of exactly the limit from a larger one
+	body, err := io.ReadAll(io.LimitReader(response.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
+
 // fetch makes an HTTP request to Replicate's API.
 func (r *Client) fetch(ctx context.Context, method, path string, body interface{}, out interface{}) error {
 	bodyBuffer := &bytes.Buffer{}
@@ -250,14 +704,20 @@ func (r *Client) fetch(ctx context.Context, method, path string, body interface{
 
 // shouldRetry returns true if the request should be retried.
 //
-// - GET requests should be retried if the response status code is 429 or 5xx.
-// - Other requests should be retried if the response status code is 429.
+//   - GET requests, and those with methods added by WithRetryMethods, should be retried
+//     if the response status code is 429, 5xx, or one added by WithRetryableStatusCodes.
+//   - Other requests should be retried if the response status code is 429.
 func (r *Client) shouldRetry(response *http.Response, method string) bool {
-	if method == http.MethodGet {
-		return response.StatusCode == 429 || (response.StatusCode >= 500 && response.StatusCode < 600)
+	if response.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	policy := r.options.retryPolicy
+	if method != http.MethodGet && !slices.Contains(policy.methods, method) {
+		return false
 	}
 
-	return response.StatusCode == 429
+	return (response.StatusCode >= 500 && response.StatusCode < 600) || slices.Contains(policy.statusCodes, response.StatusCode)
 }
 
 func constructURL(baseURL, route string) string {
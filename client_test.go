@@ -3,19 +3,27 @@ package replicate_test
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
 	"crypto/md5" // nolint:gosec
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -43,6 +51,394 @@ func TestNewClientAuthTokenFromEnv(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWithAuthScheme(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       []replicate.ClientOption
+		wantHeader string
+	}{
+		{name: "default", wantHeader: "Bearer test-token"},
+		{name: "Bearer", opts: []replicate.ClientOption{replicate.WithAuthScheme("Bearer")}, wantHeader: "Bearer test-token"},
+		{name: "Token", opts: []replicate.ClientOption{replicate.WithAuthScheme("Token")}, wantHeader: "Token test-token"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.wantHeader, r.Header.Get("Authorization"))
+				json.NewEncoder(w).Encode(replicate.Account{Username: "alice"})
+			}))
+			defer mockServer.Close()
+
+			opts := append([]replicate.ClientOption{
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			}, tc.opts...)
+			client, err := replicate.NewClient(opts...)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = client.GetCurrentAccount(ctx)
+			require.NoError(t, err)
+		})
+	}
+
+	_, err := replicate.NewClient(replicate.WithToken("test-token"), replicate.WithAuthScheme("Basic"))
+	assert.Error(t, err)
+}
+
+func TestNewClientInvalidBaseURL(t *testing.T) {
+	for _, baseURL := range []string{"api.replicate.com/v1", "/v1", "https://", "http://[::1", ""} {
+		_, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(baseURL),
+		)
+		assert.ErrorContains(t, err, "invalid base URL", baseURL)
+	}
+
+	_, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("http://localhost:8080/v1"),
+	)
+	assert.NoError(t, err)
+}
+
+func TestWithProxyURL(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests through a proxy use the absolute URL of the target
+		assert.Equal(t, "api.replicate.invalid", r.Host)
+		assert.Equal(t, "/v1/account", r.URL.Path)
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "replicate-go-test", r.Header.Get("User-Agent"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(replicate.Account{Username: "replicate"})
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithUserAgent("replicate-go-test"),
+		replicate.WithBaseURL("http://api.replicate.invalid/v1"),
+		replicate.WithProxyURL(proxyURL),
+	)
+	require.NoError(t, err)
+	assert.Nil(t, http.DefaultClient.Transport, "the default HTTP client should not be modified")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	account, err := client.GetCurrentAccount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "replicate", account.Username)
+
+	t.Run("custom transport", func(t *testing.T) {
+		_, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithHTTPClient(&http.Client{Transport: roundTripperFunc(http.DefaultTransport.RoundTrip)}),
+			replicate.WithProxyURL(proxyURL),
+		)
+		assert.ErrorContains(t, err, "cannot set proxy")
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func TestUserAgent(t *testing.T) {
+	assert.NotEmpty(t, replicate.Version())
+
+	var userAgent string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(replicate.Account{Username: "replicate"})
+	}))
+	defer mockServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	defaultUserAgent := "replicate-go/" + strings.TrimPrefix(replicate.Version(), "v")
+
+	testCases := []struct {
+		name     string
+		options  []replicate.ClientOption
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: defaultUserAgent,
+		},
+		{
+			name:     "suffix",
+			options:  []replicate.ClientOption{replicate.WithUserAgentSuffix("my-app/1.0")},
+			expected: defaultUserAgent + " my-app/1.0",
+		},
+		{
+			name:     "replaced",
+			options:  []replicate.ClientOption{replicate.WithUserAgent("my-app/1.0")},
+			expected: "my-app/1.0",
+		},
+		{
+			name: "replaced with suffix",
+			options: []replicate.ClientOption{
+				replicate.WithUserAgent("my-app/1.0"),
+				replicate.WithUserAgentSuffix("my-plugin/2.0"),
+			},
+			expected: "my-app/1.0 my-plugin/2.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			options := append([]replicate.ClientOption{
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			}, tc.options...)
+			client, err := replicate.NewClient(options...)
+			require.NoError(t, err)
+
+			_, err = client.GetCurrentAccount(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, userAgent)
+		})
+	}
+}
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithDefaultHeaders(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/predictions", r.URL.Path)
+
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant-ID"))
+		assert.Equal(t, "eu-west", r.Header.Get("X-Region"))
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithDefaultHeaders(map[string]string{
+			"X-Tenant-ID":   "acme",
+			"Authorization": "Bearer other-token",
+		}),
+		replicate.WithDefaultHeaders(map[string]string{
+			"X-Region":     "eu-west",
+			"Content-Type": "text/plain",
+		}),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.PredictionInput{"text": "Alice"}
+	version := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+	prediction, err := client.CreatePrediction(ctx, version, input, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+}
+
+type recordingLogger struct {
+	mu       sync.Mutex
+	debugs   []string
+	warnings []string
+}
+
+func (l *recordingLogger) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+}
+
+func (l *recordingLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warnings = append(l.warnings, fmt.Sprintf(format, args...))
+}
+
+func TestWithLogger(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if requests == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"detail":"Too many requests"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(replicate.Page[replicate.Prediction]{})
+	}))
+	defer mockServer.Close()
+
+	logger := &recordingLogger{}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(3, &replicate.ConstantBackoff{Base: time.Millisecond}),
+		replicate.WithLogger(logger),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, logger.debugs, 2)
+	assert.Contains(t, logger.debugs[0], "GET "+mockServer.URL+"/predictions, attempt=1")
+	assert.Contains(t, logger.debugs[1], "attempt=2")
+
+	require.Len(t, logger.warnings, 1)
+	assert.Equal(t, "retrying GET "+mockServer.URL+"/predictions after 429, delay=1ms, attempt=2", logger.warnings[0])
+}
+
+func TestWithMaxResponseBodySize(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions/small":
+			json.NewEncoder(w).Encode(replicate.Prediction{ID: "small", Status: replicate.Succeeded})
+		case "/predictions/large":
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>" + strings.Repeat("error ", 10000) + "</html>"))
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
+		replicate.WithMaxResponseBodySize(1024),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.GetPrediction(ctx, "small")
+	require.NoError(t, err)
+	assert.Equal(t, "small", prediction.ID)
+
+	_, err = client.GetPrediction(ctx, "large")
+	assert.ErrorIs(t, err, replicate.ErrResponseTooLarge)
+
+	_, err = replicate.NewClient(replicate.WithToken("test-token"), replicate.WithMaxResponseBodySize(0))
+	assert.Error(t, err)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	var requests int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(replicate.Page[replicate.Prediction]{})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRateLimit(20, 1),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		_, err := client.ListPredictions(ctx)
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&requests))
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := client.ListPredictions(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithRateLimit(0, 1),
+		)
+		assert.Error(t, err)
+
+		_, err = replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithRateLimit(1, 0),
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestWithResponseCallback(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(10-requests))
+
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+	}))
+	defer mockServer.Close()
+
+	var rateLimits []string
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithResponseCallback(func(resp *http.Response) {
+			rateLimits = append(rateLimits, resp.Header.Get("X-RateLimit-Remaining"))
+		}),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+
+	assert.Equal(t, []string{"9", "8"}, rateLimits)
+}
+
 func TestListCollections(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/collections", r.URL.Path)
@@ -159,30 +555,21 @@ func TestGetCollection(t *testing.T) {
 	assert.Empty(t, *collection.Models)
 }
 
-func TestListModels(t *testing.T) {
+func TestListModelsByOwner(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/models", r.URL.Path)
 		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/models/acme", r.URL.Path)
 
 		response := replicate.Page[replicate.Model]{
 			Results: []replicate.Model{
-				{
-					Owner:       "stability-ai",
-					Name:        "sdxl",
-					Description: "A text-to-image generative AI model that creates beautiful 1024x1024 images",
-				},
-				{
-					Owner:       "meta",
-					Name:        "codellama-13b",
-					Description: "A 13 billion parameter Llama tuned for code completion",
-				},
+				{Owner: "acme", Name: "image-upscaler", Visibility: "private"},
+				{Owner: "acme", Name: "text-classifier", Visibility: "public"},
 			},
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(response)
-		w.Write(body)
+		json.NewEncoder(w).Encode(response)
 	}))
 	defer mockServer.Close()
 
@@ -196,35 +583,199 @@ func TestListModels(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	modelsPage, err := client.ListModels(ctx)
-	assert.NoError(t, err)
-	assert.Equal(t, 2, len(modelsPage.Results))
-	assert.Equal(t, "stability-ai", modelsPage.Results[0].Owner)
-	assert.Equal(t, "sdxl", modelsPage.Results[0].Name)
-	assert.Equal(t, "meta", modelsPage.Results[1].Owner)
-	assert.Equal(t, "codellama-13b", modelsPage.Results[1].Name)
+	page, err := client.ListModelsByOwner(ctx, "acme")
+	require.NoError(t, err)
+	require.Len(t, page.Results, 2)
+	assert.Equal(t, "image-upscaler", page.Results[0].Name)
+	assert.Equal(t, "private", page.Results[0].Visibility)
+	assert.Equal(t, "text-classifier", page.Results[1].Name)
 }
 
-func TestSearchModels(t *testing.T) {
+func TestListCollectionModels(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/models", r.URL.Path)
-		assert.Equal(t, "QUERY", r.Method)
-		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/collections/super-resolution/models", r.URL.Path)
 
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			t.Fatal(err)
+		var response replicate.Page[replicate.Model]
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next := "/collections/super-resolution/models?cursor=page2"
+			response = replicate.Page[replicate.Model]{
+				Next:    &next,
+				Results: []replicate.Model{{Owner: "nightmareai", Name: "real-esrgan"}},
+			}
+		case "page2":
+			response = replicate.Page[replicate.Model]{
+				Results: []replicate.Model{{Owner: "jingyunliang", Name: "swinir"}},
+			}
+		default:
+			t.Fatalf("Unexpected cursor %q", r.URL.Query().Get("cursor"))
 		}
-		defer r.Body.Close()
 
-		assert.Equal(t, "stable diffusion", string(body))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
 
-		response := replicate.Page[replicate.Model]{
-			Results: []replicate.Model{
-				{
-					Owner:       "stability-ai",
-					Name:        "sdxl",
-					Description: "A text-to-image generative AI model that creates beautiful 1024x1024 images",
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListCollectionModels(ctx, "super-resolution")
+	require.NoError(t, err)
+
+	models, err := replicate.Collect(ctx, client, initialPage)
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, "real-esrgan", models[0].Name)
+	assert.Equal(t, "swinir", models[1].Name)
+}
+
+func TestListModels(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		response := replicate.Page[replicate.Model]{
+			Results: []replicate.Model{
+				{
+					Owner:       "stability-ai",
+					Name:        "sdxl",
+					Description: "A text-to-image generative AI model that creates beautiful 1024x1024 images",
+				},
+				{
+					Owner:       "meta",
+					Name:        "codellama-13b",
+					Description: "A 13 billion parameter Llama tuned for code completion",
+				},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(response)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	modelsPage, err := client.ListModels(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(modelsPage.Results))
+	assert.Equal(t, "stability-ai", modelsPage.Results[0].Owner)
+	assert.Equal(t, "sdxl", modelsPage.Results[0].Name)
+	assert.Equal(t, "meta", modelsPage.Results[1].Owner)
+	assert.Equal(t, "codellama-13b", modelsPage.Results[1].Name)
+}
+
+func TestListModelsWithOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  []replicate.ListModelsOption
+		expected url.Values
+	}{
+		{
+			name:     "no options",
+			expected: url.Values{},
+		},
+		{
+			name:     "hardware",
+			options:  []replicate.ListModelsOption{replicate.WithModelsHardware(replicate.HardwareCPU)},
+			expected: url.Values{"hardware": {"cpu"}},
+		},
+		{
+			name: "several hardware",
+			options: []replicate.ListModelsOption{
+				replicate.WithModelsHardware(replicate.HardwareCPU),
+				replicate.WithModelsHardware(replicate.HardwareGPUT4),
+			},
+			expected: url.Values{"hardware": {"cpu", "gpu-t4"}},
+		},
+		{
+			name:     "most run",
+			options:  []replicate.ListModelsOption{replicate.WithModelsSortBy(replicate.ModelsSortByRunCount, false)},
+			expected: url.Values{"sort_by": {"run_count"}, "sort_direction": {"desc"}},
+		},
+		{
+			name: "oldest on CPU",
+			options: []replicate.ListModelsOption{
+				replicate.WithModelsHardware(replicate.HardwareCPU),
+				replicate.WithModelsSortBy(replicate.ModelsSortByCreatedAt, true),
+			},
+			expected: url.Values{"hardware": {"cpu"}, "sort_by": {"model_created_at"}, "sort_direction": {"asc"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "/models", r.URL.Path)
+				assert.Equal(t, tc.expected, r.URL.Query())
+
+				response := replicate.Page[replicate.Model]{
+					Results: []replicate.Model{{Owner: "replicate", Name: "hello-world"}},
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				json.NewEncoder(w).Encode(response)
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			page, err := client.ListModelsWithOptions(ctx, tc.options...)
+			require.NoError(t, err)
+			require.Len(t, page.Results, 1)
+			assert.Equal(t, "hello-world", page.Results[0].Name)
+		})
+	}
+}
+
+func TestSearchModels(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models", r.URL.Path)
+		assert.Equal(t, "QUERY", r.Method)
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Body.Close()
+
+		assert.Equal(t, "stable diffusion", string(body))
+
+		response := replicate.Page[replicate.Model]{
+			Results: []replicate.Model{
+				{
+					Owner:       "stability-ai",
+					Name:        "sdxl",
+					Description: "A text-to-image generative AI model that creates beautiful 1024x1024 images",
 				},
 				{
 					Owner:       "stability-ai",
@@ -259,6 +810,210 @@ func TestSearchModels(t *testing.T) {
 	assert.Equal(t, "stable-diffusion", modelsPage.Results[1].Name)
 }
 
+func TestSearchModelsPagination(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "QUERY", r.Method)
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "stable diffusion", string(body))
+
+		var response replicate.Page[replicate.Model]
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			assert.Equal(t, "/models", r.URL.Path)
+			next := "/models?cursor=page2"
+			response = replicate.Page[replicate.Model]{
+				Next:    &next,
+				Results: []replicate.Model{{Owner: "stability-ai", Name: "sdxl"}},
+			}
+		case "page2":
+			assert.Equal(t, "/models", r.URL.Path)
+			response = replicate.Page[replicate.Model]{
+				Results: []replicate.Model{{Owner: "stability-ai", Name: "stable-diffusion"}},
+			}
+		default:
+			t.Fatalf("unexpected cursor: %s", r.URL.Query().Get("cursor"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	modelsPage, err := client.SearchModels(ctx, "stable diffusion")
+	require.NoError(t, err)
+
+	models, err := replicate.Collect(ctx, client, modelsPage)
+	require.NoError(t, err)
+	require.Len(t, models, 2)
+	assert.Equal(t, "sdxl", models[0].Name)
+	assert.Equal(t, "stable-diffusion", models[1].Name)
+}
+
+func TestSearchModelsFallsBackToPost(t *testing.T) {
+	methods := []string{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+
+		if r.Method == "QUERY" {
+			// Simulate a proxy that rejects non-standard methods
+			hj, ok := w.(http.Hijacker)
+			require.True(t, ok)
+			conn, _, err := hj.Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/models/search", r.URL.Path)
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "stable diffusion", string(body))
+
+		response := replicate.Page[replicate.Model]{
+			Results: []replicate.Model{
+				{Owner: "stability-ai", Name: "sdxl"},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("fallback", func(t *testing.T) {
+		methods = nil
+
+		client, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+		)
+		require.NoError(t, err)
+
+		modelsPage, err := client.SearchModels(ctx, "stable diffusion")
+		require.NoError(t, err)
+		require.Len(t, modelsPage.Results, 1)
+		assert.Equal(t, "sdxl", modelsPage.Results[0].Name)
+		assert.Equal(t, []string{"QUERY", http.MethodPost}, methods)
+	})
+
+	t.Run("WithSearchMethod", func(t *testing.T) {
+		methods = nil
+
+		client, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+			replicate.WithSearchMethod(http.MethodPost),
+		)
+		require.NoError(t, err)
+
+		_, err = client.SearchModels(ctx, "stable diffusion")
+		require.NoError(t, err)
+		assert.Equal(t, []string{http.MethodPost}, methods)
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		_, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithSearchMethod(http.MethodGet),
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestSearchModelsFallsBackToPostOnlyWhenMethodIsRejected(t *testing.T) {
+	testCases := []struct {
+		name         string
+		status       int
+		body         string
+		wantMethods  []string
+		wantErr      bool
+		wantAPIError bool
+	}{
+		{
+			name:        "Method not allowed",
+			status:      http.StatusMethodNotAllowed,
+			body:        `{"detail": "Method not allowed"}`,
+			wantMethods: []string{"QUERY", http.MethodPost},
+		},
+		{
+			name:         "Server error",
+			status:       http.StatusInternalServerError,
+			body:         `{"detail": "Internal server error"}`,
+			wantMethods:  []string{"QUERY"},
+			wantErr:      true,
+			wantAPIError: true,
+		},
+		{
+			name:        "Invalid response",
+			status:      http.StatusOK,
+			body:        `{"results": [`,
+			wantMethods: []string{"QUERY"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			methods := []string{}
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				methods = append(methods, r.Method)
+
+				w.Header().Set("Content-Type", "application/json")
+				if r.Method == "QUERY" {
+					w.WriteHeader(tc.status)
+					w.Write([]byte(tc.body))
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"results": [{"owner": "stability-ai", "name": "sdxl"}]}`))
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+				replicate.WithRetryPolicy(1, &replicate.ConstantBackoff{}),
+			)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = client.SearchModels(ctx, "stable diffusion")
+			if tc.wantErr {
+				assert.Error(t, err)
+				apiError := &replicate.APIError{}
+				assert.Equal(t, tc.wantAPIError, errors.As(err, &apiError))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantMethods, methods)
+		})
+	}
+}
+
 func TestGetModel(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/models/replicate/hello-world", r.URL.Path)
@@ -300,6 +1055,73 @@ func TestGetModel(t *testing.T) {
 	assert.Equal(t, "hello-world", model.Name)
 }
 
+func TestGetModelReadme(t *testing.T) {
+	readme := "# Hello World\n\nA tiny model that says hello.\n"
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/replicate/hello-world/readme", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		w.Header().Set("Content-Type", "text/markdown")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(readme))
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	content, err := client.GetModelReadme(ctx, "replicate", "hello-world")
+	assert.NoError(t, err)
+	assert.Equal(t, readme, content)
+}
+
+func TestListModelExamples(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/replicate/hello-world/examples", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		examplesPage := replicate.Page[replicate.Prediction]{
+			Results: []replicate.Prediction{
+				{
+					ID:     "ufawqhfynnddngldkgtslldrkq",
+					Status: replicate.Succeeded,
+					Input:  replicate.PredictionInput{"text": "Alice"},
+					Output: "hello Alice",
+				},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(examplesPage)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	examplesPage, err := client.ListModelExamples(ctx, "replicate", "hello-world")
+	require.NoError(t, err)
+	require.Len(t, examplesPage.Results, 1)
+	assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, examplesPage.Results[0].Input)
+	assert.Equal(t, "hello Alice", examplesPage.Results[0].Output)
+}
+
 func TestCreateModel(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -370,9 +1192,97 @@ func TestCreateModel(t *testing.T) {
 	assert.Equal(t, "", model.Description)
 }
 
-func TestDeleteModelVersion(t *testing.T) {
-	modelName := "replicate"
-	modelOwner := "hello-world"
+func TestGetOrCreateModel(t *testing.T) {
+	testCases := []struct {
+		name        string
+		status      int
+		apiError    replicate.APIError
+		wantCreated bool
+		wantErr     bool
+	}{
+		{
+			name:        "Created",
+			status:      http.StatusCreated,
+			wantCreated: true,
+		},
+		{
+			name:     "Conflict",
+			status:   http.StatusConflict,
+			apiError: replicate.APIError{Title: "Conflict", Detail: "A model with that name and owner already exists."},
+		},
+		{
+			name:     "Unprocessable entity for existing model",
+			status:   http.StatusUnprocessableEntity,
+			apiError: replicate.APIError{Detail: "A model with that name and owner already exists."},
+		},
+		{
+			name:     "Unprocessable entity for invalid options",
+			status:   http.StatusUnprocessableEntity,
+			apiError: replicate.APIError{Detail: "Invalid hardware."},
+			wantErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == "/models":
+					w.WriteHeader(tc.status)
+					if tc.status == http.StatusCreated {
+						body, _ := json.Marshal(replicate.Model{Owner: "owner", Name: "name", Visibility: "private"})
+						w.Write(body)
+						return
+					}
+					body, _ := json.Marshal(tc.apiError)
+					w.Write(body)
+				case r.Method == http.MethodGet && r.URL.Path == "/models/owner/name":
+					w.WriteHeader(http.StatusOK)
+					body, _ := json.Marshal(replicate.Model{Owner: "owner", Name: "name", Visibility: "public"})
+					w.Write(body)
+				default:
+					t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			)
+			require.NotNil(t, client)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			options := replicate.CreateModelOptions{
+				Visibility: "private",
+				Hardware:   replicate.HardwareCPU,
+			}
+			model, err := client.GetOrCreateModel(ctx, "owner", "name", options)
+			if tc.wantErr {
+				apiErr := &replicate.APIError{}
+				assert.ErrorAs(t, err, &apiErr)
+				assert.Equal(t, tc.status, apiErr.Status)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "owner", model.Owner)
+			assert.Equal(t, "name", model.Name)
+			if tc.wantCreated {
+				assert.Equal(t, "private", model.Visibility)
+			} else {
+				assert.Equal(t, "public", model.Visibility)
+			}
+		})
+	}
+}
+
+func TestDeleteModelVersion(t *testing.T) {
+	modelName := "replicate"
+	modelOwner := "hello-world"
 	versionID := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodDelete, r.Method)
@@ -395,6 +1305,159 @@ func TestDeleteModelVersion(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeleteModelVersionWithConfirmIfPredictionsExist(t *testing.T) {
+	usedVersionID := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+	unusedVersionID := "a9758cbfbd5f3c2094457d996681af52552901775aa2d6dd0b17fd15df959bef"
+
+	var listed, deleted []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		assert.Equal(t, http.MethodGet, r.Method)
+		listed = append(listed, r.URL.Path)
+
+		var page replicate.Page[replicate.Prediction]
+		switch r.URL.Path {
+		case "/predictions":
+			assert.Equal(t, "id,version", r.URL.Query().Get("include"))
+			next := "/predictions/page2"
+			page = replicate.Page[replicate.Prediction]{
+				Next:    &next,
+				Results: []replicate.Prediction{{ID: "ufawqhfynnddngldkgtslldrkq", Version: "other"}},
+			}
+		case "/predictions/page2":
+			page = replicate.Page[replicate.Prediction]{
+				Results: []replicate.Prediction{{ID: "rrr4z55ocneqzikepnug6xezpe", Version: usedVersionID}},
+			}
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = client.DeleteModelVersion(ctx, "replicate", "hello-world", usedVersionID, replicate.WithConfirmIfPredictionsExist())
+	assert.ErrorIs(t, err, replicate.ErrModelVersionHasPredictions)
+	assert.Equal(t, []string{"/predictions", "/predictions/page2"}, listed)
+	assert.Empty(t, deleted)
+
+	listed = nil
+	err = client.DeleteModelVersion(ctx, "replicate", "hello-world", unusedVersionID, replicate.WithConfirmIfPredictionsExist())
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+	assert.Equal(t, []string{"/models/replicate/hello-world/versions/" + unusedVersionID}, deleted)
+
+	// Without the option, the version is deleted without checking
+	listed, deleted = nil, nil
+	err = client.DeleteModelVersion(ctx, "replicate", "hello-world", usedVersionID)
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+	assert.Equal(t, []string{"/models/replicate/hello-world/versions/" + usedVersionID}, deleted)
+}
+
+func TestDeleteModelVersionWithConfirmIfPredictionsExistStopsListing(t *testing.T) {
+	versionID := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+
+	listed, deleted := 0, 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted++
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		// Every page has a next page, with predictions of other versions
+		listed++
+		next := fmt.Sprintf("/predictions?cursor=%d", listed)
+		page := replicate.Page[replicate.Prediction]{
+			Next:    &next,
+			Results: []replicate.Prediction{{ID: "ufawqhfynnddngldkgtslldrkq", Version: "other"}},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = client.DeleteModelVersion(ctx, "replicate", "hello-world", versionID, replicate.WithConfirmIfPredictionsExist())
+	require.NoError(t, err)
+	assert.Equal(t, 10, listed)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestDeleteModelVersionWithResult(t *testing.T) {
+	testCases := []struct {
+		name         string
+		statusCode   int
+		body         string
+		wantAccepted bool
+		wantRawJSON  string
+	}{
+		{name: "ok", statusCode: http.StatusOK, body: `{}`, wantRawJSON: `{}`},
+		{name: "accepted", statusCode: http.StatusAccepted, body: `{"id":"del_123","status":"processing"}`, wantAccepted: true, wantRawJSON: `{"id":"del_123","status":"processing"}`},
+		{name: "accepted without body", statusCode: http.StatusAccepted, wantAccepted: true},
+		{name: "no content", statusCode: http.StatusNoContent},
+		{name: "not JSON", statusCode: http.StatusOK, body: "deleted"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodDelete, r.Method)
+				assert.Equal(t, "/models/hello-world/replicate/versions/5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", r.URL.Path)
+				w.WriteHeader(tc.statusCode)
+				w.Write([]byte(tc.body))
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := client.DeleteModelVersionWithResult(ctx, "hello-world", "replicate", "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa")
+			require.NoError(t, err)
+			assert.Equal(t, tc.statusCode, result.StatusCode)
+			assert.Equal(t, tc.wantAccepted, result.Accepted())
+			if tc.wantRawJSON == "" {
+				assert.Nil(t, result.RawJSON())
+			} else {
+				assert.JSONEq(t, tc.wantRawJSON, string(result.RawJSON()))
+			}
+		})
+	}
+}
+
 func TestListModelVersions(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/models/replicate/hello-world/versions", r.URL.Path)
@@ -430,6 +1493,127 @@ func TestListModelVersions(t *testing.T) {
 	assert.Equal(t, "b21cbe271e65c1718f2999b038c18b45e21e4fba961181fbfae9342fc53b9e05", versionsPage.Results[1].ID)
 }
 
+func TestListAllModelVersions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		var versionsPage replicate.Page[replicate.ModelVersion]
+		switch r.URL.Path {
+		case "/models/replicate/hello-world/versions":
+			next := "/models/replicate/hello-world/versions/page2"
+			versionsPage = replicate.Page[replicate.ModelVersion]{
+				Next: &next,
+				Results: []replicate.ModelVersion{
+					{ID: "v2", CreatedAt: "2023-02-01T00:00:00.000000Z"},
+					{ID: "v1", CreatedAt: "2023-01-01T00:00:00.000000Z"},
+				},
+			}
+		case "/models/replicate/hello-world/versions/page2":
+			versionsPage = replicate.Page[replicate.ModelVersion]{
+				Results: []replicate.ModelVersion{
+					{ID: "v3", CreatedAt: "2023-03-01T00:00:00.000000Z"},
+				},
+			}
+		case "/models/replicate/empty/versions":
+			versionsPage = replicate.Page[replicate.ModelVersion]{
+				Results: []replicate.ModelVersion{},
+			}
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(versionsPage)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	versions, err := client.ListAllModelVersions(ctx, "replicate", "hello-world")
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "v3", versions[0].ID)
+	assert.Equal(t, "v2", versions[1].ID)
+	assert.Equal(t, "v1", versions[2].ID)
+
+	latest, err := client.LatestVersion(ctx, "replicate", "hello-world")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", latest.ID)
+
+	_, err = client.LatestVersion(ctx, "replicate", "empty")
+	assert.ErrorIs(t, err, replicate.ErrNoModelVersions)
+}
+
+func TestGetModelVersionByPrefix(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		var versionsPage replicate.Page[replicate.ModelVersion]
+		switch r.URL.Path {
+		case "/models/replicate/hello-world/versions":
+			next := "/models/replicate/hello-world/versions/page2"
+			versionsPage = replicate.Page[replicate.ModelVersion]{
+				Next: &next,
+				Results: []replicate.ModelVersion{
+					{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"},
+					{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccab"},
+				},
+			}
+		case "/models/replicate/hello-world/versions/page2":
+			versionsPage = replicate.Page[replicate.ModelVersion]{
+				Results: []replicate.ModelVersion{
+					{ID: "a9758cbfbd5f3c2094457d996681af52552901775aa2d6dd0b17fd15df959bef"},
+				},
+			}
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(versionsPage)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	version, err := client.GetModelVersionByPrefix(ctx, "replicate", "hello-world", "a9758cb")
+	require.NoError(t, err)
+	assert.Equal(t, "a9758cbfbd5f3c2094457d996681af52552901775aa2d6dd0b17fd15df959bef", version.ID)
+
+	version, err = client.GetModelVersionByPrefix(ctx, "replicate", "hello-world", "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccab")
+	require.NoError(t, err)
+	assert.Equal(t, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccab", version.ID)
+
+	_, err = client.GetModelVersionByPrefix(ctx, "replicate", "hello-world", "5c7d5dc")
+	assert.ErrorIs(t, err, replicate.ErrAmbiguousModelVersion)
+
+	_, err = client.GetModelVersionByPrefix(ctx, "replicate", "hello-world", "ffff")
+	assert.ErrorIs(t, err, replicate.ErrModelVersionNotFound)
+
+	_, err = client.GetModelVersionByPrefix(ctx, "replicate", "hello-world", "")
+	assert.Error(t, err)
+}
+
 func TestGetModelVersion(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/models/replicate/hello-world/versions/version1", r.URL.Path)
@@ -543,6 +1727,91 @@ func TestCreatePrediction(t *testing.T) {
 	assert.Equal(t, "https://streaming.api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq", prediction.URLs["stream"])
 }
 
+func TestCreatePredictionWithFileInput(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		defer r.Body.Close()
+
+		switch r.URL.Path {
+		case "/files":
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			require.NoError(t, err)
+
+			part, err := multipart.NewReader(r.Body, params["boundary"]).NextPart()
+			require.NoError(t, err)
+
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+
+			var file *replicate.File
+			switch string(content) {
+			case "hello":
+				assert.Equal(t, "form-data; name=\"content\"; filename=\"hello.txt\"", part.Header.Get("Content-Disposition"))
+				assert.Equal(t, "text/plain; charset=utf-8", part.Header.Get("Content-Type"))
+				file = &replicate.File{ID: "file-hello", URLs: map[string]string{"get": "https://api.replicate.com/v1/files/file-hello"}}
+			case "world":
+				file = &replicate.File{ID: "file-world", URLs: map[string]string{"get": "https://api.replicate.com/v1/files/file-world"}}
+			default:
+				t.Fatalf("unexpected file content: %q", content)
+			}
+
+			responseBytes, err := json.Marshal(file)
+			require.NoError(t, err)
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write(responseBytes)
+		case "/predictions":
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]interface{}{
+				"text":  "Alice",
+				"file":  "https://api.replicate.com/v1/files/file-hello",
+				"other": "https://api.replicate.com/v1/files/file-world",
+			}, requestBody["input"])
+
+			response := replicate.Prediction{
+				ID:        "ufawqhfynnddngldkgtslldrkq",
+				Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:    "starting",
+				Input:     requestBody["input"].(map[string]interface{}),
+				CreatedAt: "2022-04-26T22:13:06.224088Z",
+			}
+			responseBytes, err := json.Marshal(response)
+			require.NoError(t, err)
+
+			w.WriteHeader(http.StatusCreated)
+			w.Write(responseBytes)
+		default:
+			t.Fatalf("unexpected request path: %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.PredictionInput{
+		"text":  "Alice",
+		"file":  replicate.FileRef{Reader: strings.NewReader("hello"), Filename: "hello.txt"},
+		"other": strings.NewReader("world"),
+	}
+	version := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+	prediction, err := client.CreatePrediction(ctx, version, input, nil, false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, "https://api.replicate.com/v1/files/file-hello", prediction.Input["file"])
+}
+
 func TestCreatePredictionWithDeployment(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -624,46 +1893,19 @@ func TestCreatePredictionWithDeployment(t *testing.T) {
 	assert.Equal(t, "https://streaming.api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq", prediction.URLs["stream"])
 }
 
-func TestCreatePredictionWithModel(t *testing.T) {
+func TestCreateWithWebhookEventsFilter(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
-		assert.Equal(t, "/models/owner/model/predictions", r.URL.Path)
-
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			t.Fatal(err)
-		}
-		defer r.Body.Close()
 
 		var requestBody map[string]interface{}
-		err = json.Unmarshal(body, &requestBody)
-		if err != nil {
-			t.Fatal(err)
-		}
+		err := json.NewDecoder(r.Body).Decode(&requestBody)
+		require.NoError(t, err)
 
-		assert.Equal(t, map[string]interface{}{"text": "Alice"}, requestBody["input"])
-		assert.Equal(t, "https://example.com/webhook", requestBody["webhook"])
-		assert.Equal(t, []interface{}{"start", "completed"}, requestBody["webhook_events_filter"])
-		assert.Equal(t, true, requestBody["stream"])
-
-		response := replicate.Prediction{
-			ID:        "ufawqhfynnddngldkgtslldrkq",
-			Model:     "owner/model",
-			Status:    "starting",
-			Input:     map[string]interface{}{"text": "Alice"},
-			Output:    nil,
-			Error:     nil,
-			Logs:      nil,
-			Metrics:   nil,
-			CreatedAt: "2022-04-26T22:13:06.224088Z",
-		}
-		responseBytes, err := json.Marshal(response)
-		if err != nil {
-			t.Fatal(err)
-		}
+		assert.Equal(t, "https://example.com/webhook", requestBody["webhook"], r.URL.Path)
+		assert.Equal(t, []interface{}{"start", "completed"}, requestBody["webhook_events_filter"], r.URL.Path)
 
 		w.WriteHeader(http.StatusCreated)
-		w.Write(responseBytes)
+		json.NewEncoder(w).Encode(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting})
 	}))
 	defer mockServer.Close()
 
@@ -678,42 +1920,73 @@ func TestCreatePredictionWithModel(t *testing.T) {
 	defer cancel()
 
 	input := replicate.PredictionInput{"text": "Alice"}
-	webhook := replicate.Webhook{
+	webhook := &replicate.Webhook{
 		URL:    "https://example.com/webhook",
-		Events: []replicate.WebhookEventType{"start", "completed"},
-	}
-	prediction, err := client.CreatePredictionWithModel(ctx, "owner", "model", input, &webhook, true)
-	if err != nil {
-		t.Fatal(err)
+		Events: []replicate.WebhookEventType{replicate.WebhookEventStart, replicate.WebhookEventCompleted},
 	}
 
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
-	assert.Equal(t, "owner/model", prediction.Model)
-	assert.Equal(t, replicate.Starting, prediction.Status)
+	t.Run("version", func(t *testing.T) {
+		_, err := client.CreatePrediction(ctx, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, webhook, false)
+		require.NoError(t, err)
+	})
+
+	t.Run("model", func(t *testing.T) {
+		_, err := client.CreatePredictionWithModel(ctx, "owner", "model", input, webhook, false)
+		require.NoError(t, err)
+	})
+
+	t.Run("deployment", func(t *testing.T) {
+		_, err := client.CreatePredictionWithDeployment(ctx, "owner", "name", input, webhook, false)
+		require.NoError(t, err)
+	})
+
+	t.Run("training", func(t *testing.T) {
+		_, err := client.CreateTraining(ctx, "owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", "owner/destination", replicate.TrainingInput{}, webhook)
+		require.NoError(t, err)
+	})
 }
 
-func TestCancelPrediction(t *testing.T) {
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodPost, r.Method)
-		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq/cancel", r.URL.Path)
+func TestNewWebhook(t *testing.T) {
+	webhook, err := replicate.NewWebhook("https://example.com/webhook",
+		replicate.WebhookEventStart,
+		replicate.WebhookEventCompleted,
+		replicate.WebhookEventStart,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/webhook", webhook.URL)
+	assert.Equal(t, []replicate.WebhookEventType{replicate.WebhookEventStart, replicate.WebhookEventCompleted}, webhook.Events)
 
-		response := replicate.Prediction{
-			ID:     "ufawqhfynnddngldkgtslldrkq",
-			Status: replicate.Canceled,
-		}
-		responseBytes, err := json.Marshal(response)
-		if err != nil {
-			t.Fatal(err)
-		}
+	webhook, err = replicate.NewWebhook("https://example.com/webhook")
+	require.NoError(t, err)
+	assert.Empty(t, webhook.Events)
 
-		w.WriteHeader(http.StatusOK)
-		w.Write(responseBytes)
+	_, err = replicate.NewWebhook("https://example.com/webhook", "complete")
+	assert.ErrorIs(t, err, replicate.ErrUnknownWebhookEvent)
+
+	_, err = replicate.NewWebhook("", replicate.WebhookEventCompleted)
+	assert.Error(t, err)
+}
+
+func TestWithDefaultWebhook(t *testing.T) {
+	defaultWebhook := &replicate.Webhook{
+		URL:    "https://example.com/default",
+		Events: []replicate.WebhookEventType{replicate.WebhookEventCompleted},
+	}
+
+	var trainingBody map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/models/owner/model/versions/632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532/trainings", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&trainingBody))
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(replicate.Training{ID: "zz4ibbonubfz7carwiefibzgga", Status: replicate.Starting})
 	}))
 	defer mockServer.Close()
 
 	client, err := replicate.NewClient(
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithDefaultWebhook(defaultWebhook),
 	)
 	require.NotNil(t, client)
 	require.NoError(t, err)
@@ -721,124 +1994,61 @@ func TestCancelPrediction(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	prediction, err := client.CancelPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
-	assert.NoError(t, err)
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
-	assert.Equal(t, replicate.Canceled, prediction.Status)
-}
+	// Changing the webhook after creating the client doesn't affect the default
+	defaultWebhook.URL = "https://example.com/changed"
 
-func TestPredictionProgress(t *testing.T) {
-	prediction := replicate.Prediction{
-		ID:        "ufawqhfynnddngldkgtslldrkq",
-		Model:     "replicate/hello-world",
-		Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-		Status:    "starting",
-		Input:     map[string]interface{}{"text": "Alice"},
-		Output:    nil,
-		Error:     nil,
-		Logs:      nil,
-		Metrics:   nil,
-		CreatedAt: "2022-04-26T22:13:06.224088Z",
-		URLs: map[string]string{
-			"get":    "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
-			"cancel": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel",
-			"stream": "https://streaming.api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
+	testCases := []struct {
+		name     string
+		webhook  *replicate.Webhook
+		wantBody string
+	}{
+		{
+			name:     "inherits default",
+			wantBody: `{"input":{},"webhook":"https://example.com/default","webhook_events_filter":["completed"]}`,
+		},
+		{
+			name:     "overrides default",
+			webhook:  &replicate.Webhook{URL: "https://example.com/explicit"},
+			wantBody: `{"input":{},"webhook":"https://example.com/explicit"}`,
 		},
 	}
 
-	lines := []string{
-		"Using seed: 12345",
-		"0%|          | 0/5 [00:00<?, ?it/s]",
-		"20%|██        | 1/5 [00:00<00:01, 21.38it/s]",
-		"40%|████▍     | 2/5 [00:01<00:01, 22.46it/s]",
-		"60%|████▍     | 3/5 [00:01<00:01, 22.46it/s]",
-		"80%|████████  | 4/5 [00:01<00:00, 22.86it/s]",
-		"100%|██████████| 5/5 [00:02<00:00, 22.26it/s]",
-	}
-	logs := ""
-
-	for i, line := range lines {
-		logs = logs + "\n" + line
-		prediction.Logs = &logs
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := []replicate.CreatePredictionOption{
+				replicate.WithPredictionModel("owner", "model"),
+				replicate.WithPredictionInput(replicate.PredictionInput{}),
+			}
+			if tc.webhook != nil {
+				opts = append(opts, replicate.WithPredictionWebhook(tc.webhook))
+			}
 
-		progress := prediction.Progress()
+			req, err := client.BuildCreatePredictionRequest(ctx, opts...)
+			require.NoError(t, err)
 
-		switch i {
-		case 0:
-			prediction.Status = replicate.Processing
-			assert.Nil(t, progress)
-		case 1:
-			assert.NotNil(t, progress)
-			assert.Equal(t, 0, progress.Current)
-			assert.Equal(t, 5, progress.Total)
-			assert.Equal(t, 0.0, progress.Percentage)
-		case 2:
-			assert.NotNil(t, progress)
-			assert.Equal(t, 1, progress.Current)
-			assert.Equal(t, 5, progress.Total)
-			assert.Equal(t, 0.2, progress.Percentage)
-		case 3:
-			assert.NotNil(t, progress)
-			assert.Equal(t, 2, progress.Current)
-			assert.Equal(t, 5, progress.Total)
-			assert.Equal(t, 0.4, progress.Percentage)
-		case 4:
-			assert.NotNil(t, progress)
-			assert.Equal(t, 3, progress.Current)
-			assert.Equal(t, 5, progress.Total)
-			assert.Equal(t, 0.6, progress.Percentage)
-		case 5:
-			assert.NotNil(t, progress)
-			assert.Equal(t, 4, progress.Current)
-			assert.Equal(t, 5, progress.Total)
-			assert.Equal(t, 0.8, progress.Percentage)
-		case 6:
-			assert.NotNil(t, progress)
-			prediction.Status = replicate.Succeeded
-			assert.Equal(t, 5, progress.Current)
-			assert.Equal(t, 5, progress.Total)
-			assert.Equal(t, 1.0, progress.Percentage)
-		}
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.wantBody, string(body))
+		})
 	}
-}
-
-func TestListPredictions(t *testing.T) {
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/predictions", r.URL.Path)
-		assert.Equal(t, http.MethodGet, r.Method)
-
-		var response replicate.Page[replicate.Prediction]
-
-		mockCursor := "cD0yMDIyLTAxLTIxKzIzJTNBMTglM0EyNC41MzAzNTclMkIwMCUzQTAw"
 
-		switch r.URL.Query().Get("cursor") {
-		case "":
-			next := "/predictions?cursor=" + mockCursor
-			response = replicate.Page[replicate.Prediction]{
-				Previous: nil,
-				Next:     &next,
-				Results: []replicate.Prediction{
-					{ID: "ufawqhfynnddngldkgtslldrkq"},
-				},
-			}
-		case mockCursor:
-			previous := "/predictions?cursor=" + mockCursor
-			response = replicate.Page[replicate.Prediction]{
-				Previous: &previous,
-				Next:     nil,
-				Results: []replicate.Prediction{
-					{ID: "rrr4z55ocneqzikepnug6xezpe"},
-				},
-			}
-		}
+	_, err = client.CreateTraining(ctx, "owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", "owner/new-model", replicate.TrainingInput{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/default", trainingBody["webhook"])
 
-		responseBytes, err := json.Marshal(response)
-		if err != nil {
-			t.Fatal(err)
-		}
+	_, err = replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithDefaultWebhook(&replicate.Webhook{
+			URL:    "https://example.com/default",
+			Events: []replicate.WebhookEventType{"finished"},
+		}),
+	)
+	assert.ErrorIs(t, err, replicate.ErrUnknownWebhookEvent)
+}
 
-		w.WriteHeader(http.StatusOK)
-		w.Write(responseBytes)
+func TestCreateWithUnknownWebhookEvent(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
 	}))
 	defer mockServer.Close()
 
@@ -852,54 +2062,65 @@ func TestListPredictions(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	initialPage, err := client.ListPredictions(ctx)
-	if err != nil {
-		t.Fatal(err)
+	webhook := &replicate.Webhook{
+		URL:    "https://example.com/webhook",
+		Events: []replicate.WebhookEventType{replicate.WebhookEventStart, "finished"},
 	}
 
-	resultsChan, errChan := replicate.Paginate(ctx, client, initialPage)
+	err = webhook.Validate()
+	assert.ErrorIs(t, err, replicate.ErrUnknownWebhookEvent)
+	assert.Contains(t, err.Error(), `"finished"`)
 
-	var predictions []replicate.Prediction
-	for results := range resultsChan {
-		predictions = append(predictions, results...)
-	}
+	_, err = client.CreatePrediction(ctx, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{}, webhook, false)
+	assert.ErrorIs(t, err, replicate.ErrUnknownWebhookEvent)
 
-	select {
-	case err := <-errChan:
+	_, err = client.CreateTraining(ctx, "owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", "owner/destination", replicate.TrainingInput{}, webhook)
+	assert.ErrorIs(t, err, replicate.ErrUnknownWebhookEvent)
+
+	valid := &replicate.Webhook{URL: "https://example.com/webhook", Events: replicate.WebhookEventAll}
+	assert.NoError(t, valid.Validate())
+}
+
+func TestCreatePredictionWithModel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/models/owner/model/predictions", r.URL.Path)
+
+		body, err := io.ReadAll(r.Body)
 		if err != nil {
 			t.Fatal(err)
 		}
-	default:
-	}
+		defer r.Body.Close()
 
-	assert.Len(t, predictions, 2)
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", predictions[0].ID)
-	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", predictions[1].ID)
-}
+		var requestBody map[string]interface{}
+		err = json.Unmarshal(body, &requestBody)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-func TestGetPrediction(t *testing.T) {
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodGet, r.Method)
-		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+		assert.Equal(t, map[string]interface{}{"text": "Alice"}, requestBody["input"])
+		assert.Equal(t, "https://example.com/webhook", requestBody["webhook"])
+		assert.Equal(t, []interface{}{"start", "completed"}, requestBody["webhook_events_filter"])
+		assert.Equal(t, true, requestBody["stream"])
 
-		prediction := &replicate.Prediction{
+		response := replicate.Prediction{
 			ID:        "ufawqhfynnddngldkgtslldrkq",
-			Model:     "replicate/hello-world",
-			Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-			Status:    replicate.Succeeded,
-			Input:     replicate.PredictionInput{"text": "Alice"},
-			Output:    map[string]interface{}{"text": "Hello, Alice"},
+			Model:     "owner/model",
+			Status:    "starting",
+			Input:     map[string]interface{}{"text": "Alice"},
+			Output:    nil,
+			Error:     nil,
+			Logs:      nil,
+			Metrics:   nil,
 			CreatedAt: "2022-04-26T22:13:06.224088Z",
-			URLs: map[string]string{
-				"get":    "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
-				"cancel": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel",
-			},
+		}
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(prediction)
-		w.Write(body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write(responseBytes)
 	}))
 	defer mockServer.Close()
 
@@ -913,62 +2134,82 @@ func TestGetPrediction(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
-	assert.NoError(t, err)
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
-	assert.Equal(t, replicate.Succeeded, prediction.Status)
-	assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, prediction.Input)
-	assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, prediction.Output)
-	assert.Equal(t, "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq", prediction.URLs["get"])
-	assert.Equal(t, "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel", prediction.URLs["cancel"])
-}
+	input := replicate.PredictionInput{"text": "Alice"}
+	webhook := replicate.Webhook{
+		URL:    "https://example.com/webhook",
+		Events: []replicate.WebhookEventType{"start", "completed"},
+	}
+	prediction, err := client.CreatePredictionWithModel(ctx, "owner", "model", input, &webhook, true)
+	if err != nil {
+		t.Fatal(err)
+	}
 
-func TestWait(t *testing.T) {
-	statuses := []replicate.Status{replicate.Starting, replicate.Processing, replicate.Succeeded}
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, "owner/model", prediction.Model)
+	assert.Equal(t, replicate.Starting, prediction.Status)
+}
 
-	i := 0
+func TestCreatePredictionWithVersion(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodGet, r.Method)
-		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/predictions", r.URL.Path)
 
-		prediction := &replicate.Prediction{
-			ID:        "ufawqhfynnddngldkgtslldrkq",
-			Model:     "replicate/hello-world",
-			Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-			Status:    statuses[i],
-			Input:     replicate.PredictionInput{"text": "Alice"},
-			CreatedAt: "2022-04-26T22:13:06.224088Z",
+		var requestBody map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+		assert.Equal(t, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", requestBody["version"])
+		assert.Equal(t, map[string]interface{}{"text": "Alice"}, requestBody["input"])
+
+		response := replicate.Prediction{
+			ID:      "ufawqhfynnddngldkgtslldrkq",
+			Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+			Status:  replicate.Starting,
 		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
 
-		if statuses[i] == replicate.Succeeded {
-			prediction.Output = map[string]interface{}{"text": "Hello, Alice"}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
 
-			startedAt := "2022-04-26T22:13:06.324088Z"
-			prediction.StartedAt = &startedAt
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-			completedAt := "2022-04-26T22:13:07.224088Z"
-			prediction.CompletedAt = &completedAt
+	version := &replicate.ModelVersion{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"}
+	prediction, err := client.CreatePredictionWithVersion(ctx, version, replicate.PredictionInput{"text": "Alice"}, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, version.ID, prediction.Version)
 
-			predictTime := 0.5
-			totalTime := 1.0
-			inputTokenCount := 1
-			outputTokenCount := 2
-			prediction.Metrics = &replicate.PredictionMetrics{
-				PredictTime:      &predictTime,
-				TotalTime:        &totalTime,
-				InputTokenCount:  &inputTokenCount,
-				OutputTokenCount: &outputTokenCount,
-			}
-		}
+	_, err = client.CreatePredictionWithVersion(ctx, nil, replicate.PredictionInput{}, nil, false)
+	assert.Error(t, err)
+}
 
-		if i < len(statuses)-1 {
-			i++
+func TestCreatePredictionWithOptions(t *testing.T) {
+	var (
+		path        string
+		prefer      string
+		requestBody map[string]interface{}
+	)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		path = r.URL.Path
+		prefer = r.Header.Get("Prefer")
+
+		requestBody = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+
+		status := replicate.Starting
+		if prefer != "" {
+			status = replicate.Succeeded
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(prediction)
-		w.Write(body)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: status})
 	}))
 	defer mockServer.Close()
 
@@ -979,61 +2220,192 @@ func TestWait(t *testing.T) {
 	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	prediction := &replicate.Prediction{
-		ID:        "ufawqhfynnddngldkgtslldrkq",
-		Model:     "replicate/hello-world",
-		Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-		Status:    replicate.Starting,
-		Input:     replicate.PredictionInput{"text": "Alice"},
-		CreatedAt: "2022-04-26T22:13:06.224088Z",
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.PredictionInput{"text": "Alice"}
+
+	t.Run("Model", func(t *testing.T) {
+		prediction, err := client.CreatePredictionWithOptions(ctx,
+			replicate.WithPredictionModel("replicate", "hello-world"),
+			replicate.WithPredictionInput(input),
+			replicate.WithPredictionWait(),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, replicate.Succeeded, prediction.Status)
+		assert.Equal(t, "/models/replicate/hello-world/predictions", path)
+		assert.Equal(t, "wait", prefer)
+		assert.Equal(t, map[string]interface{}{"input": map[string]interface{}{"text": "Alice"}}, requestBody)
+	})
+
+	t.Run("Version", func(t *testing.T) {
+		_, err := client.CreatePredictionWithOptions(ctx,
+			replicate.WithPredictionVersion("5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"),
+			replicate.WithPredictionInput(input),
+			replicate.WithPredictionStream(),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, "/predictions", path)
+		assert.Empty(t, prefer)
+		assert.Equal(t, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", requestBody["version"])
+		assert.Equal(t, true, requestBody["stream"])
+	})
+
+	t.Run("Deployment", func(t *testing.T) {
+		_, err := client.CreatePredictionWithOptions(ctx,
+			replicate.WithPredictionDeployment("acme", "image-upscaler"),
+			replicate.WithPredictionInput(input),
+			replicate.WithPredictionWebhook(&replicate.Webhook{
+				URL:    "https://example.com/webhook",
+				Events: []replicate.WebhookEventType{"completed"},
+			}),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, "/deployments/acme/image-upscaler/predictions", path)
+		assert.Equal(t, "https://example.com/webhook", requestBody["webhook"])
+		assert.Equal(t, []interface{}{"completed"}, requestBody["webhook_events_filter"])
+	})
+
+	t.Run("Missing target", func(t *testing.T) {
+		_, err := client.CreatePredictionWithOptions(ctx, replicate.WithPredictionInput(input))
+		assert.ErrorContains(t, err, "exactly one of WithPredictionModel, WithPredictionVersion, or WithPredictionDeployment")
+	})
+
+	t.Run("Conflicting targets", func(t *testing.T) {
+		_, err := client.CreatePredictionWithOptions(ctx,
+			replicate.WithPredictionModel("replicate", "hello-world"),
+			replicate.WithPredictionDeployment("acme", "image-upscaler"),
+			replicate.WithPredictionInput(input),
+		)
+		assert.ErrorContains(t, err, "exactly one of WithPredictionModel, WithPredictionVersion, or WithPredictionDeployment")
+	})
+}
+
+func TestBuildCreatePredictionRequest(t *testing.T) {
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("https://api.example.com/v1"),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond))
-	assert.NoError(t, err)
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
-	assert.Equal(t, replicate.Succeeded, prediction.Status)
-	assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, prediction.Input)
-	assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, prediction.Output)
-	assert.Equal(t, "2022-04-26T22:13:06.324088Z", *prediction.StartedAt)
-	assert.Equal(t, "2022-04-26T22:13:07.224088Z", *prediction.CompletedAt)
-	assert.Equal(t, 0.5, *prediction.Metrics.PredictTime)
-	assert.Equal(t, 1.0, *prediction.Metrics.TotalTime)
-	assert.Equal(t, 1, *prediction.Metrics.InputTokenCount)
-	assert.Equal(t, 2, *prediction.Metrics.OutputTokenCount)
+	req, err := client.BuildCreatePredictionRequest(ctx,
+		replicate.WithPredictionModel("owner", "model"),
+		replicate.WithPredictionInput(replicate.PredictionInput{"text": "Alice"}),
+		replicate.WithPredictionWait(),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "https://api.example.com/v1/models/owner/model/predictions", req.URL.String())
+	assert.Equal(t, "wait", req.Header.Get("Prefer"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"input":{"text":"Alice"}}`, string(body))
+	assert.NotContains(t, fmt.Sprintf("%v", req.Header), "test-token")
+
+	for seconds, want := range map[int]string{30: "wait=30", 0: "wait=1", 120: "wait=60"} {
+		req, err := client.BuildCreatePredictionRequest(ctx,
+			replicate.WithPredictionModel("owner", "model"),
+			replicate.WithPredictionWaitTimeout(seconds),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, want, req.Header.Get("Prefer"))
+	}
+
+	// Files aren't uploaded, so the unreachable base URL isn't requested
+	req, err = client.BuildCreatePredictionRequest(ctx,
+		replicate.WithPredictionModel("owner", "model"),
+		replicate.WithPredictionInput(replicate.PredictionInput{
+			"image": replicate.FileRef{Reader: strings.NewReader("image data"), Filename: "image.png"},
+			"mask":  strings.NewReader("mask data"),
+		}),
+	)
+	require.NoError(t, err)
+
+	body, err = io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"input":{"image":"<upload: image.png>","mask":"<upload>"}}`, string(body))
+
+	_, err = client.BuildCreatePredictionRequest(ctx, replicate.WithPredictionInput(replicate.PredictionInput{}))
+	assert.Error(t, err)
 }
 
-func TestWaitAsync(t *testing.T) {
-	statuses := []replicate.Status{replicate.Starting, replicate.Processing, replicate.Succeeded}
+func TestCreatePredictionDoesNotMutateInput(t *testing.T) {
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("https://api.example.com/v1"),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
 
-	i := 0
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodGet, r.Method)
-		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-		prediction := &replicate.Prediction{
-			ID:        "ufawqhfynnddngldkgtslldrkq",
-			Model:     "replicate/hello-world",
-			Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-			Status:    statuses[i],
-			Input:     replicate.PredictionInput{"text": "Alice"},
-			CreatedAt: "2022-04-26T22:13:06.224088Z",
-		}
+	file := &replicate.File{
+		ID:   "file1",
+		Name: "image.png",
+		URLs: map[string]string{
+			"get": "https://api.example.com/v1/files/file1",
+		},
+	}
+	input := replicate.PredictionInput{"image": file}
+
+	for i := 0; i < 2; i++ {
+		req, err := client.BuildCreatePredictionRequest(ctx,
+			replicate.WithPredictionModel("owner", "model"),
+			replicate.WithPredictionInput(input),
+		)
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"input":{"image":"https://api.example.com/v1/files/file1"}}`, string(body))
+		assert.Same(t, file, input["image"])
+	}
 
-		if statuses[i] == replicate.Succeeded {
-			prediction.Output = map[string]interface{}{"text": "Hello, Alice"}
-		}
+	req, err := client.BuildCreatePredictionRequest(ctx,
+		replicate.WithPredictionModel("owner", "model"),
+		replicate.WithPredictionInput(input),
+		replicate.WithPredictionFileConversion(false),
+	)
+	require.NoError(t, err)
 
-		if i < len(statuses)-1 {
-			i++
+	var body struct {
+		Input struct {
+			Image replicate.File `json:"image"`
+		} `json:"input"`
+	}
+	require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+	assert.Equal(t, "file1", body.Input.Image.ID)
+	assert.Equal(t, file.URLs, body.Input.Image.URLs)
+}
+
+func TestCancelPrediction(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq/cancel", r.URL.Path)
+
+		response := replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Canceled,
+		}
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
 		}
 
-		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(prediction)
-		w.Write(body)
+		w.Write(responseBytes)
 	}))
 	defer mockServer.Close()
 
@@ -1044,59 +2416,33 @@ func TestWaitAsync(t *testing.T) {
 	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	prediction := &replicate.Prediction{
-		ID:        "ufawqhfynnddngldkgtslldrkq",
-		Model:     "replicate/hello-world",
-		Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-		Status:    replicate.Starting,
-		Input:     replicate.PredictionInput{"text": "Alice"},
-		CreatedAt: "2022-04-26T22:13:06.224088Z",
-	}
-
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	predChan, errChan := client.WaitAsync(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond))
-	var lastStatus replicate.Status
-	for pred := range predChan {
-		lastStatus = pred.Status
-		if pred.Status == replicate.Succeeded {
-			assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", pred.ID)
-			assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, pred.Input)
-			assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, pred.Output)
-			break
-		}
-	}
-
-	if err := <-errChan; err != nil {
-		t.Fatal(err)
-	}
-
-	assert.Equal(t, replicate.Succeeded, lastStatus)
+	prediction, err := client.CancelPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	assert.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, replicate.Canceled, prediction.Status)
 }
 
-func TestRun(t *testing.T) {
+func TestCancelFinishedPrediction(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.URL.Path {
-		case "/predictions":
-			assert.Equal(t, http.MethodPost, r.Method)
-			prediction := replicate.Prediction{
-				ID:      "gtsllfynndufawqhdngldkdrkq",
-				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:  replicate.Starting,
-			}
-			json.NewEncoder(w).Encode(prediction)
-		case "/predictions/gtsllfynndufawqhdngldkdrkq":
-			assert.Equal(t, http.MethodGet, r.Method)
-			prediction := replicate.Prediction{
-				ID:      "gtsllfynndufawqhdngldkdrkq",
-				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:  replicate.Succeeded,
-				Output:  "Hello, world!",
-			}
-			json.NewEncoder(w).Encode(prediction)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/predictions/succeeded/cancel":
+			json.NewEncoder(w).Encode(replicate.Prediction{ID: "succeeded", Status: replicate.Succeeded})
+		case r.Method == http.MethodPost && r.URL.Path == "/predictions/conflict/cancel":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"detail":"Prediction has already completed"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/predictions/conflict":
+			json.NewEncoder(w).Encode(replicate.Prediction{ID: "conflict", Status: replicate.Failed})
+		case r.Method == http.MethodPost && r.URL.Path == "/predictions/running/cancel":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"detail":"Conflict"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/predictions/running":
+			json.NewEncoder(w).Encode(replicate.Prediction{ID: "running", Status: replicate.Processing})
 		default:
-			t.Fatalf("Unexpected request to %s", r.URL.Path)
+			t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
 		}
 	}))
 	defer mockServer.Close()
@@ -1105,49 +2451,1853 @@ func TestRun(t *testing.T) {
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
 	)
+	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	ctx := context.Background()
-	input := replicate.PredictionInput{"prompt": "Hello"}
-	output, err := client.Run(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	prediction, err := client.CancelPrediction(ctx, "succeeded")
 	require.NoError(t, err)
-	assert.NotNil(t, output)
-	assert.Equal(t, "Hello, world!", output)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+
+	prediction, err = client.CancelPrediction(ctx, "conflict")
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Failed, prediction.Status)
+
+	_, err = client.CancelPrediction(ctx, "running")
+	apiErr := &replicate.APIError{}
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusConflict, apiErr.Status)
 }
 
-func TestRunWithVersionlessModel(t *testing.T) {
+func TestCancelPredictions(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.URL.Path {
-		case "/models/owner/model/predictions":
-			assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, http.MethodPost, r.Method)
 
-			// Check the request body
-			var requestBody map[string]interface{}
-			err := json.NewDecoder(r.Body).Decode(&requestBody)
-			require.NoError(t, err)
-			assert.Equal(t, "Hello", requestBody["input"].(map[string]interface{})["prompt"])
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/predictions/"), "/cancel")
+		if id == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(replicate.APIError{Status: http.StatusNotFound, Detail: "Not found."})
+			return
+		}
 
-			// Respond with a prediction
-			prediction := replicate.Prediction{
-				ID:     "ndufagtsllfynwqhdngldkdrkq",
-				Model:  "owner/model",
-				Status: replicate.Starting,
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(replicate.Prediction{ID: id, Status: replicate.Canceled})
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ids := []string{"a", "b", "c", "missing", "d", "e"}
+	results, err := client.CancelPredictions(ctx, ids, replicate.WithCancelConcurrency(2))
+	require.NoError(t, err)
+
+	assert.Len(t, results, len(ids))
+	for _, id := range ids {
+		if id == "missing" {
+			assert.ErrorContains(t, results[id], "Not found.")
+		} else {
+			assert.NoError(t, results[id])
+		}
+	}
+	assert.LessOrEqual(t, maxInFlight, 2)
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := client.CancelPredictions(ctx, ids)
+		assert.ErrorIs(t, err, context.Canceled)
+		for _, id := range ids {
+			assert.ErrorIs(t, results[id], context.Canceled)
+		}
+	})
+}
+
+func TestPredictionProgress(t *testing.T) {
+	prediction := replicate.Prediction{
+		ID:        "ufawqhfynnddngldkgtslldrkq",
+		Model:     "replicate/hello-world",
+		Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+		Status:    "starting",
+		Input:     map[string]interface{}{"text": "Alice"},
+		Output:    nil,
+		Error:     nil,
+		Logs:      nil,
+		Metrics:   nil,
+		CreatedAt: "2022-04-26T22:13:06.224088Z",
+		URLs: map[string]string{
+			"get":    "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
+			"cancel": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel",
+			"stream": "https://streaming.api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
+		},
+	}
+
+	lines := []string{
+		"Using seed: 12345",
+		"0%|          | 0/5 [00:00<?, ?it/s]",
+		"20%|██        | 1/5 [00:00<00:01, 21.38it/s]",
+		"40%|████▍     | 2/5 [00:01<00:01, 22.46it/s]",
+		"60%|████▍     | 3/5 [00:01<00:01, 22.46it/s]",
+		"80%|████████  | 4/5 [00:01<00:00, 22.86it/s]",
+		"100%|██████████| 5/5 [00:02<00:00, 22.26it/s]",
+	}
+	logs := ""
+
+	for i, line := range lines {
+		logs = logs + "\n" + line
+		prediction.Logs = &logs
+
+		progress := prediction.Progress()
+
+		switch i {
+		case 0:
+			prediction.Status = replicate.Processing
+			assert.Nil(t, progress)
+		case 1:
+			assert.NotNil(t, progress)
+			assert.Equal(t, 0, progress.Current)
+			assert.Equal(t, 5, progress.Total)
+			assert.Equal(t, 0.0, progress.Percentage)
+		case 2:
+			assert.NotNil(t, progress)
+			assert.Equal(t, 1, progress.Current)
+			assert.Equal(t, 5, progress.Total)
+			assert.Equal(t, 0.2, progress.Percentage)
+		case 3:
+			assert.NotNil(t, progress)
+			assert.Equal(t, 2, progress.Current)
+			assert.Equal(t, 5, progress.Total)
+			assert.Equal(t, 0.4, progress.Percentage)
+		case 4:
+			assert.NotNil(t, progress)
+			assert.Equal(t, 3, progress.Current)
+			assert.Equal(t, 5, progress.Total)
+			assert.Equal(t, 0.6, progress.Percentage)
+		case 5:
+			assert.NotNil(t, progress)
+			assert.Equal(t, 4, progress.Current)
+			assert.Equal(t, 5, progress.Total)
+			assert.Equal(t, 0.8, progress.Percentage)
+		case 6:
+			assert.NotNil(t, progress)
+			prediction.Status = replicate.Succeeded
+			assert.Equal(t, 5, progress.Current)
+			assert.Equal(t, 5, progress.Total)
+			assert.Equal(t, 1.0, progress.Percentage)
+		}
+	}
+}
+
+func TestPredictionProgressWithParser(t *testing.T) {
+	logs := "Loading model\nStep 1/4: encoding\nStep 3/4: denoising\nSaving output"
+	prediction := replicate.Prediction{
+		ID:     "ufawqhfynnddngldkgtslldrkq",
+		Status: replicate.Processing,
+		Logs:   &logs,
+	}
+
+	// tqdm is the default
+	assert.Nil(t, prediction.Progress())
+
+	progress := prediction.Progress(replicate.WithProgressParser(replicate.ParseStepProgress))
+	require.NotNil(t, progress)
+	assert.Equal(t, 3, progress.Current)
+	assert.Equal(t, 4, progress.Total)
+	assert.Equal(t, 0.75, progress.Percentage)
+
+	// Parsers are tried in order
+	custom := func(logs string) *replicate.PredictionProgress {
+		if strings.Contains(logs, "Saving output") {
+			return &replicate.PredictionProgress{Percentage: 0.99}
+		}
+		return nil
+	}
+	progress = prediction.Progress(replicate.WithProgressParser(custom, replicate.ParseStepProgress))
+	require.NotNil(t, progress)
+	assert.Equal(t, 0.99, progress.Percentage)
+}
+
+func BenchmarkPredictionProgress(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString("Using seed: 12345\n")
+	for i := 0; i <= 1000; i++ {
+		fmt.Fprintf(&sb, "%d%%|██        | %d/1000 [00:00<00:01, 21.38it/s]\n", i/10, i)
+	}
+	logs := sb.String()
+	prediction := replicate.Prediction{Logs: &logs}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if prediction.Progress() == nil {
+			b.Fatal("expected progress")
+		}
+	}
+}
+
+func TestListPredictions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		var response replicate.Page[replicate.Prediction]
+
+		mockCursor := "cD0yMDIyLTAxLTIxKzIzJTNBMTglM0EyNC41MzAzNTclMkIwMCUzQTAw"
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next := "/predictions?cursor=" + mockCursor
+			response = replicate.Page[replicate.Prediction]{
+				Previous: nil,
+				Next:     &next,
+				Results: []replicate.Prediction{
+					{ID: "ufawqhfynnddngldkgtslldrkq"},
+				},
 			}
-			w.WriteHeader(http.StatusCreated)
-			json.NewEncoder(w).Encode(prediction)
-		case "/predictions/ndufagtsllfynwqhdngldkdrkq":
-			assert.Equal(t, http.MethodGet, r.Method)
-			prediction := replicate.Prediction{
-				ID:     "ndufagtsllfynwqhdngldkdrkq",
-				Model:  "owner/model",
-				Status: replicate.Succeeded,
-				Output: "Hello, world!",
+		case mockCursor:
+			previous := "/predictions?cursor=" + mockCursor
+			response = replicate.Page[replicate.Prediction]{
+				Previous: &previous,
+				Next:     nil,
+				Results: []replicate.Prediction{
+					{ID: "rrr4z55ocneqzikepnug6xezpe"},
+				},
 			}
-			json.NewEncoder(w).Encode(prediction)
-		default:
-			t.Fatalf("Unexpected request to %s", r.URL.Path)
 		}
+
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseBytes)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListPredictions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultsChan, errChan := replicate.Paginate(ctx, client, initialPage)
+
+	var predictions []replicate.Prediction
+	for results := range resultsChan {
+		predictions = append(predictions, results...)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+	}
+
+	assert.Len(t, predictions, 2)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", predictions[0].ID)
+	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", predictions[1].ID)
+}
+
+func TestListPredictionsWithOptions(t *testing.T) {
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createdBefore := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		query := r.URL.Query()
+		assert.Equal(t, "succeeded", query.Get("status"))
+		assert.Equal(t, "2024-01-01T00:00:00Z", query.Get("created_after"))
+		assert.Equal(t, "2024-02-01T00:00:00Z", query.Get("created_before"))
+
+		var response replicate.Page[replicate.Prediction]
+
+		mockCursor := "cD0yMDIyLTAxLTIxKzIzJTNBMTglM0EyNC41MzAzNTclMkIwMCUzQTAw"
+
+		switch query.Get("cursor") {
+		case "":
+			next := "/predictions?" + query.Encode() + "&cursor=" + mockCursor
+			response = replicate.Page[replicate.Prediction]{
+				Next: &next,
+				Results: []replicate.Prediction{
+					{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Succeeded},
+				},
+			}
+		case mockCursor:
+			response = replicate.Page[replicate.Prediction]{
+				Results: []replicate.Prediction{
+					{ID: "rrr4z55ocneqzikepnug6xezpe", Status: replicate.Succeeded},
+				},
+			}
+		}
+
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseBytes)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListPredictionsWithOptions(ctx,
+		replicate.WithStatusFilter(replicate.Succeeded),
+		replicate.WithCreatedAfter(createdAfter),
+		replicate.WithCreatedBefore(createdBefore),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultsChan, errChan := replicate.Paginate(ctx, client, initialPage)
+
+	var predictions []replicate.Prediction
+	for results := range resultsChan {
+		predictions = append(predictions, results...)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Fatal(err)
+		}
+	default:
+	}
+
+	assert.Len(t, predictions, 2)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", predictions[0].ID)
+	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", predictions[1].ID)
+}
+
+func TestCollect(t *testing.T) {
+	failSecondPage := false
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions", r.URL.Path)
+
+		var response replicate.Page[replicate.Prediction]
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next := "/predictions?cursor=2"
+			response = replicate.Page[replicate.Prediction]{
+				Next:    &next,
+				Results: []replicate.Prediction{{ID: "ufawqhfynnddngldkgtslldrkq"}, {ID: "gtsllfynndufawqhdngldkdrkq"}},
+			}
+		case "2":
+			if failSecondPage {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(replicate.APIError{Detail: "Invalid cursor."})
+				return
+			}
+			response = replicate.Page[replicate.Prediction]{
+				Results: []replicate.Prediction{{ID: "rrr4z55ocneqzikepnug6xezpe"}},
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListPredictions(ctx)
+	require.NoError(t, err)
+
+	predictions, err := replicate.Collect(ctx, client, initialPage)
+	require.NoError(t, err)
+	require.Len(t, predictions, 3)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", predictions[0].ID)
+	assert.Equal(t, "gtsllfynndufawqhdngldkdrkq", predictions[1].ID)
+	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", predictions[2].ID)
+
+	failSecondPage = true
+	predictions, err = replicate.Collect(ctx, client, initialPage)
+	assert.ErrorContains(t, err, "Invalid cursor.")
+	assert.Nil(t, predictions)
+}
+
+func TestMarshalJSONPreservesUnknownFields(t *testing.T) {
+	data := []byte(`{"id":"ufawqhfynnddngldkgtslldrkq","status":"succeeded","new_field":{"nested":true}}`)
+
+	var prediction replicate.Prediction
+	require.NoError(t, json.Unmarshal(data, &prediction))
+
+	// Both values and pointers marshal from the original JSON
+	marshaled, err := json.Marshal(prediction)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(marshaled))
+
+	marshaled, err = json.Marshal(map[string]interface{}{"prediction": &prediction})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"prediction":`+string(data)+`}`, string(marshaled))
+
+	var model replicate.Model
+	require.NoError(t, json.Unmarshal([]byte(`{"owner":"acme","name":"model","new_field":1}`), &model))
+	marshaled, err = json.Marshal(model)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"owner":"acme","name":"model","new_field":1}`, string(marshaled))
+
+	var file replicate.File
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"file1","new_field":"value"}`), &file))
+	marshaled, err = json.Marshal(file)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"file1","new_field":"value"}`, string(marshaled))
+
+	// Without the original JSON, the struct's fields are marshaled
+	marshaled, err = json.Marshal(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting})
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(marshaled, &fields))
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", fields["id"])
+	assert.Equal(t, "starting", fields["status"])
+	assert.NotContains(t, fields, "new_field")
+}
+
+func TestPredictionSource(t *testing.T) {
+	assert.True(t, replicate.SourceWeb.IsValid())
+	assert.True(t, replicate.SourceAPI.IsValid())
+	assert.False(t, replicate.Source("").IsValid())
+
+	var prediction replicate.Prediction
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"ufawqhfynnddngldkgtslldrkq","source":"batch"}`), &prediction))
+	assert.Equal(t, replicate.Source("batch"), prediction.Source)
+	assert.False(t, prediction.Source.IsValid())
+
+	// The source is set by the API, so it's never sent when creating a prediction
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL("https://api.example.com/v1"),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := client.BuildCreatePredictionRequest(ctx,
+		replicate.WithPredictionVersion("5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"),
+		replicate.WithPredictionInput(replicate.PredictionInput{"text": "Alice"}),
+		replicate.WithPredictionStream(),
+	)
+	require.NoError(t, err)
+
+	var body map[string]interface{}
+	require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+	assert.NotContains(t, body, "source")
+}
+
+func TestGetPrediction(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+
+		prediction := &replicate.Prediction{
+			ID:        "ufawqhfynnddngldkgtslldrkq",
+			Model:     "replicate/hello-world",
+			Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+			Status:    replicate.Succeeded,
+			Input:     replicate.PredictionInput{"text": "Alice"},
+			Output:    map[string]interface{}{"text": "Hello, Alice"},
+			CreatedAt: "2022-04-26T22:13:06.224088Z",
+			URLs: map[string]string{
+				"get":    "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
+				"cancel": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel",
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	assert.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, prediction.Input)
+	assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, prediction.Output)
+	assert.Equal(t, "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq", prediction.URLs["get"])
+	assert.Equal(t, "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel", prediction.URLs["cancel"])
+}
+
+func TestGetPredictionWithFields(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "id,status,output", r.URL.Query().Get("include"))
+
+		var response interface{}
+		switch r.URL.Path {
+		case "/predictions/ufawqhfynnddngldkgtslldrkq":
+			response = map[string]interface{}{
+				"id":     "ufawqhfynnddngldkgtslldrkq",
+				"status": "succeeded",
+				"output": "Hello, world!",
+			}
+		case "/predictions":
+			response = map[string]interface{}{
+				"results": []map[string]interface{}{
+					{"id": "ufawqhfynnddngldkgtslldrkq", "status": "succeeded", "output": "Hello, world!"},
+				},
+			}
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction, err := client.GetPredictionWithOptions(ctx, "ufawqhfynnddngldkgtslldrkq",
+		replicate.WithPredictionFields("id", "status", "output"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, "Hello, world!", prediction.Output)
+	assert.Nil(t, prediction.Logs)
+	assert.Nil(t, prediction.Metrics)
+
+	page, err := client.ListPredictionsWithOptions(ctx,
+		replicate.WithListPredictionFields("id", "status"),
+		replicate.WithListPredictionFields("output"),
+	)
+	require.NoError(t, err)
+	require.Len(t, page.Results, 1)
+	assert.Nil(t, page.Results[0].Logs)
+}
+
+func TestReloadPrediction(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+
+		prediction := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Succeeded,
+			Output: map[string]interface{}{"text": "Hello, Alice"},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	reference := prediction
+
+	err = client.ReloadPrediction(ctx, prediction)
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, reference.Status)
+	assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, reference.Output)
+
+	err = client.ReloadPrediction(ctx, &replicate.Prediction{})
+	assert.ErrorContains(t, err, "prediction has no ID")
+}
+
+func TestWait(t *testing.T) {
+	statuses := []replicate.Status{replicate.Starting, replicate.Processing, replicate.Succeeded}
+
+	i := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+
+		prediction := &replicate.Prediction{
+			ID:        "ufawqhfynnddngldkgtslldrkq",
+			Model:     "replicate/hello-world",
+			Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+			Status:    statuses[i],
+			Input:     replicate.PredictionInput{"text": "Alice"},
+			CreatedAt: "2022-04-26T22:13:06.224088Z",
+		}
+
+		if statuses[i] == replicate.Succeeded {
+			prediction.Output = map[string]interface{}{"text": "Hello, Alice"}
+
+			startedAt := "2022-04-26T22:13:06.324088Z"
+			prediction.StartedAt = &startedAt
+
+			completedAt := "2022-04-26T22:13:07.224088Z"
+			prediction.CompletedAt = &completedAt
+
+			predictTime := 0.5
+			totalTime := 1.0
+			inputTokenCount := 1
+			outputTokenCount := 2
+			prediction.Metrics = &replicate.PredictionMetrics{
+				PredictTime:      &predictTime,
+				TotalTime:        &totalTime,
+				InputTokenCount:  &inputTokenCount,
+				OutputTokenCount: &outputTokenCount,
+			}
+		}
+
+		if i < len(statuses)-1 {
+			i++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	prediction := &replicate.Prediction{
+		ID:        "ufawqhfynnddngldkgtslldrkq",
+		Model:     "replicate/hello-world",
+		Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+		Status:    replicate.Starting,
+		Input:     replicate.PredictionInput{"text": "Alice"},
+		CreatedAt: "2022-04-26T22:13:06.224088Z",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond))
+	assert.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+	assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, prediction.Input)
+	assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, prediction.Output)
+	assert.Equal(t, "2022-04-26T22:13:06.324088Z", *prediction.StartedAt)
+	assert.Equal(t, "2022-04-26T22:13:07.224088Z", *prediction.CompletedAt)
+	assert.Equal(t, 0.5, *prediction.Metrics.PredictTime)
+	assert.Equal(t, 1.0, *prediction.Metrics.TotalTime)
+	assert.Equal(t, 1, *prediction.Metrics.InputTokenCount)
+	assert.Equal(t, 2, *prediction.Metrics.OutputTokenCount)
+}
+
+func TestWaitAsync(t *testing.T) {
+	statuses := []replicate.Status{replicate.Starting, replicate.Processing, replicate.Succeeded}
+
+	i := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+
+		prediction := &replicate.Prediction{
+			ID:        "ufawqhfynnddngldkgtslldrkq",
+			Model:     "replicate/hello-world",
+			Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+			Status:    statuses[i],
+			Input:     replicate.PredictionInput{"text": "Alice"},
+			CreatedAt: "2022-04-26T22:13:06.224088Z",
+		}
+
+		if statuses[i] == replicate.Succeeded {
+			prediction.Output = map[string]interface{}{"text": "Hello, Alice"}
+		}
+
+		if i < len(statuses)-1 {
+			i++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	prediction := &replicate.Prediction{
+		ID:        "ufawqhfynnddngldkgtslldrkq",
+		Model:     "replicate/hello-world",
+		Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+		Status:    replicate.Starting,
+		Input:     replicate.PredictionInput{"text": "Alice"},
+		CreatedAt: "2022-04-26T22:13:06.224088Z",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	predChan, errChan := client.WaitAsync(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond))
+	var lastStatus replicate.Status
+	for pred := range predChan {
+		lastStatus = pred.Status
+		if pred.Status == replicate.Succeeded {
+			assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", pred.ID)
+			assert.Equal(t, replicate.PredictionInput{"text": "Alice"}, pred.Input)
+			assert.Equal(t, map[string]interface{}{"text": "Hello, Alice"}, pred.Output)
+			break
+		}
+	}
+
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, replicate.Succeeded, lastStatus)
+}
+
+func TestWaitAsyncStream(t *testing.T) {
+	const id = "ufawqhfynnddngldkgtslldrkq"
+
+	newServer := func(t *testing.T, statuses []replicate.Status, stream string) (*httptest.Server, *int) {
+		requests := 0
+		var mockServer *httptest.Server
+		mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/stream" {
+				w.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(w, stream)
+				return
+			}
+
+			assert.Equal(t, http.MethodGet, r.Method)
+			assert.Equal(t, "/predictions/"+id, r.URL.Path)
+
+			prediction := &replicate.Prediction{
+				ID:     id,
+				Status: statuses[min(requests, len(statuses)-1)],
+				URLs:   map[string]string{"stream": mockServer.URL + "/stream"},
+			}
+			requests++
+
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			json.NewEncoder(w).Encode(prediction)
+		}))
+		t.Cleanup(mockServer.Close)
+		return mockServer, &requests
+	}
+
+	wait := func(t *testing.T, mockServer *httptest.Server, prediction *replicate.Prediction) []replicate.Status {
+		client, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+			replicate.WithRetryPolicy(1, &replicate.ConstantBackoff{Base: time.Millisecond}),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var statuses []replicate.Status
+		predChan, errChan := client.WaitAsyncStream(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond))
+		for {
+			select {
+			case pred := <-predChan:
+				statuses = append(statuses, pred.Status)
+			case err := <-errChan:
+				require.NoError(t, err)
+				return statuses
+			}
+		}
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		mockServer, requests := newServer(t, []replicate.Status{replicate.Succeeded}, "event: output\ndata: Hello\n\nevent: done\ndata: {}\n\n")
+
+		prediction := &replicate.Prediction{
+			ID:     id,
+			Status: replicate.Starting,
+			URLs:   map[string]string{"stream": mockServer.URL + "/stream"},
+		}
+		statuses := wait(t, mockServer, prediction)
+
+		assert.Equal(t, []replicate.Status{replicate.Succeeded}, statuses)
+		assert.Equal(t, replicate.Succeeded, prediction.Status)
+		assert.Equal(t, 1, *requests)
+	})
+
+	t.Run("stream ends early", func(t *testing.T) {
+		mockServer, requests := newServer(t, []replicate.Status{replicate.Processing, replicate.Processing, replicate.Succeeded}, "event: output\ndata: Hello\n\n")
+
+		prediction := &replicate.Prediction{
+			ID:     id,
+			Status: replicate.Starting,
+			URLs:   map[string]string{"stream": mockServer.URL + "/stream"},
+		}
+		statuses := wait(t, mockServer, prediction)
+
+		assert.Equal(t, []replicate.Status{replicate.Processing, replicate.Processing, replicate.Succeeded}, statuses)
+		assert.Equal(t, replicate.Succeeded, prediction.Status)
+		assert.Equal(t, 3, *requests)
+	})
+
+	t.Run("no stream", func(t *testing.T) {
+		mockServer, requests := newServer(t, []replicate.Status{replicate.Processing, replicate.Succeeded}, "")
+
+		prediction := &replicate.Prediction{ID: id, Status: replicate.Starting}
+		statuses := wait(t, mockServer, prediction)
+
+		assert.Equal(t, []replicate.Status{replicate.Processing, replicate.Succeeded}, statuses)
+		assert.Equal(t, 2, *requests)
+	})
+
+	t.Run("options with spare capacity", func(t *testing.T) {
+		mockServer, _ := newServer(t, []replicate.Status{replicate.Processing, replicate.Succeeded}, "event: output\ndata: Hello\n\n")
+
+		client, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+			replicate.WithRetryPolicy(1, &replicate.ConstantBackoff{Base: time.Millisecond}),
+		)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		opts := make([]replicate.WaitOption, 0, 3)
+		opts = append(opts, replicate.WithPollingInterval(1*time.Nanosecond), replicate.WithTimeout(time.Minute))
+
+		prediction := &replicate.Prediction{
+			ID:     id,
+			Status: replicate.Starting,
+			URLs:   map[string]string{"stream": mockServer.URL + "/stream"},
+		}
+		predChan, errChan := client.WaitAsyncStream(ctx, prediction, opts...)
+		for done := false; !done; {
+			select {
+			case <-predChan:
+			case err := <-errChan:
+				require.NoError(t, err)
+				done = true
+			}
+		}
+
+		// Polling for the rest of the timeout doesn't write past the caller's options
+		assert.Nil(t, opts[:3][2])
+	})
+}
+
+func TestWaitWithMaxAttempts(t *testing.T) {
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+		requests++
+
+		prediction := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Processing,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond), replicate.WithMaxAttempts(3))
+	assert.ErrorIs(t, err, replicate.ErrWaitTimeout)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, replicate.Processing, prediction.Status)
+}
+
+// closeIdleTransport records calls to CloseIdleConnections.
+type closeIdleTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *closeIdleTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestClientCloseStopsWaits(t *testing.T) {
+	var status atomic.Value
+	status.Store(replicate.Processing)
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prediction := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: status.Load().(replicate.Status),
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(prediction)
+	}))
+	defer mockServer.Close()
+
+	transport := &closeIdleTransport{RoundTripper: http.DefaultTransport}
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Waits in progress stop when the client is closed
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	waitErr := make(chan error, 1)
+	go func() {
+		waitErr <- client.Wait(ctx, prediction, replicate.WithPollingInterval(10*time.Millisecond))
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, client.Close())
+	assert.ErrorIs(t, <-waitErr, context.Canceled)
+	assert.NoError(t, ctx.Err(), "wait should stop before the context is done")
+
+	// HTTP clients passed in by the caller are left open
+	assert.Equal(t, 0, transport.closed)
+
+	// Waits started after Close still poll
+	status.Store(replicate.Succeeded)
+	prediction = &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(1*time.Nanosecond))
+	require.NoError(t, err)
+	assert.Equal(t, replicate.Succeeded, prediction.Status)
+}
+
+func TestWaitWithTimeout(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prediction := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: replicate.Processing,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prediction := &replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting}
+	err = client.Wait(ctx, prediction, replicate.WithPollingInterval(10*time.Millisecond), replicate.WithTimeout(50*time.Millisecond))
+	assert.ErrorIs(t, err, replicate.ErrWaitTimeout)
+	assert.NoError(t, ctx.Err())
+
+	err = client.Wait(ctx, prediction, replicate.WithTimeout(-1*time.Second))
+	assert.ErrorContains(t, err, "timeout must not be negative")
+}
+
+func TestStreamPredictionLogs(t *testing.T) {
+	responses := []struct {
+		status replicate.Status
+		logs   string
+	}{
+		{replicate.Starting, ""},
+		{replicate.Processing, "Loading model\nStep 1"},
+		{replicate.Processing, "Loading model\nStep 1\nStep 2\n"},
+		{replicate.Succeeded, "Loading model\nStep 1\nStep 2\nDone"},
+	}
+
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/predictions/ufawqhfynnddngldkgtslldrkq", r.URL.Path)
+
+		response := responses[min(requests, len(responses)-1)]
+		requests++
+
+		prediction := &replicate.Prediction{
+			ID:     "ufawqhfynnddngldkgtslldrkq",
+			Status: response.status,
+		}
+		if response.logs != "" {
+			prediction.Logs = &response.logs
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(prediction)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logChan, errChan := client.StreamPredictionLogs(ctx, "ufawqhfynnddngldkgtslldrkq", replicate.WithPollingInterval(1*time.Nanosecond))
+
+	var lines []string
+	for line := range logChan {
+		lines = append(lines, line)
+	}
+
+	assert.Equal(t, []string{"Loading model", "Step 1", "Step 2", "Done"}, lines)
+	assert.NoError(t, <-errChan)
+	assert.Equal(t, len(responses), requests)
+}
+
+func TestStreamTrainingLogs(t *testing.T) {
+	responses := []struct {
+		status replicate.Status
+		logs   string
+	}{
+		{replicate.Starting, ""},
+		{replicate.Processing, "Loading base model\nflux_train_replicate:   0%|          | 0/1000 [00:00<?, ?it/s]\n"},
+		{replicate.Processing, "Loading base model\nflux_train_replicate:   0%|          | 0/1000 [00:00<?, ?it/s]\nflux_train_replicate:  40%|████      | 400/1000 [05:12<07:48, 1.28it/s, lr: 4e-04 loss: 3.2e-01]\n"},
+		{replicate.Succeeded, "Loading base model\nflux_train_replicate:   0%|          | 0/1000 [00:00<?, ?it/s]\nflux_train_replicate:  40%|████      | 400/1000 [05:12<07:48, 1.28it/s, lr: 4e-04 loss: 3.2e-01]\nSaving weights"},
+	}
+
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/trainings/zz4ibbonubfz7carwiefibzgga", r.URL.Path)
+
+		response := responses[min(requests, len(responses)-1)]
+		requests++
+
+		training := &replicate.Training{
+			ID:     "zz4ibbonubfz7carwiefibzgga",
+			Status: response.status,
+		}
+		if response.logs != "" {
+			training.Logs = &response.logs
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(training)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	logChan, errChan := client.StreamTrainingLogs(ctx, "zz4ibbonubfz7carwiefibzgga", replicate.WithPollingInterval(1*time.Nanosecond))
+
+	var lines []string
+	for line := range logChan {
+		lines = append(lines, line)
+	}
+
+	require.Len(t, lines, 4)
+	assert.Equal(t, "Loading base model", lines[0])
+	assert.Equal(t, "Saving weights", lines[3])
+	assert.NoError(t, <-errChan)
+
+	training, err := client.GetTraining(ctx, "zz4ibbonubfz7carwiefibzgga")
+	require.NoError(t, err)
+
+	progress := training.Progress()
+	require.NotNil(t, progress)
+	assert.Equal(t, 400, progress.Current)
+	assert.Equal(t, 1000, progress.Total)
+	assert.Equal(t, 0.4, progress.Percentage)
+}
+
+func TestRun(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions":
+			assert.Equal(t, http.MethodPost, r.Method)
+			prediction := replicate.Prediction{
+				ID:      "gtsllfynndufawqhdngldkdrkq",
+				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:  replicate.Starting,
+			}
+			json.NewEncoder(w).Encode(prediction)
+		case "/predictions/gtsllfynndufawqhdngldkdrkq":
+			assert.Equal(t, http.MethodGet, r.Method)
+			prediction := replicate.Prediction{
+				ID:      "gtsllfynndufawqhdngldkdrkq",
+				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:  replicate.Succeeded,
+				Output:  "Hello, world!",
+			}
+			json.NewEncoder(w).Encode(prediction)
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	input := replicate.PredictionInput{"prompt": "Hello"}
+	output, err := client.Run(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil)
+
+	require.NoError(t, err)
+	assert.NotNil(t, output)
+	assert.Equal(t, "Hello, world!", output)
+}
+
+func TestRunWithAsync(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/predictions", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Prefer"))
+
+		prediction := replicate.Prediction{
+			ID:      "ufawqhfynnddngldkgtslldrkq",
+			Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+			Status:  replicate.Starting,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(prediction)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	output, err := client.RunWithOptions(ctx, "replicate/hello-world:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{"text": "Alice"}, nil,
+		replicate.WithBlockUntilDone(),
+		replicate.WithAsync(),
+	)
+	require.NoError(t, err)
+
+	prediction, ok := output.(*replicate.Prediction)
+	require.True(t, ok)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, replicate.Starting, prediction.Status)
+}
+
+func TestRunBatch(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/predictions", r.URL.Path)
+
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		var requestBody struct {
+			Input map[string]interface{} `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+		name := requestBody.Input["name"].(string)
+
+		prediction := replicate.Prediction{
+			ID:      "prediction-" + name,
+			Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+			Status:  replicate.Succeeded,
+			Output:  "Hello, " + name,
+		}
+		if name == "Bob" {
+			prediction.Status = replicate.Failed
+			prediction.Output = nil
+			prediction.Error = "name not allowed"
+		}
+		json.NewEncoder(w).Encode(prediction)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	names := []string{"Alice", "Bob", "Carol", "Dave", "Erin"}
+	inputs := make([]replicate.PredictionInput, len(names))
+	for i, name := range names {
+		inputs[i] = replicate.PredictionInput{"name": name}
+	}
+
+	outputs, errs := client.RunBatch(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", inputs,
+		replicate.WithBlockUntilDone(), replicate.WithBatchConcurrency(2))
+	require.Len(t, outputs, len(names))
+	require.Len(t, errs, len(names))
+
+	for i, name := range names {
+		if name == "Bob" {
+			modelErr := &replicate.ModelError{}
+			assert.ErrorAs(t, errs[i], &modelErr)
+			assert.Nil(t, outputs[i])
+			continue
+		}
+		assert.NoError(t, errs[i])
+		assert.Equal(t, "Hello, "+name, outputs[i])
+	}
+	assert.LessOrEqual(t, maxInFlight, 2)
+}
+
+func TestRunWithVersionlessModel(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/models/owner/model/predictions":
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			// Check the request body
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			require.NoError(t, err)
+			assert.Equal(t, "Hello", requestBody["input"].(map[string]interface{})["prompt"])
+
+			// Respond with a prediction
+			prediction := replicate.Prediction{
+				ID:     "ndufagtsllfynwqhdngldkdrkq",
+				Model:  "owner/model",
+				Status: replicate.Starting,
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(prediction)
+		case "/predictions/ndufagtsllfynwqhdngldkdrkq":
+			assert.Equal(t, http.MethodGet, r.Method)
+			prediction := replicate.Prediction{
+				ID:     "ndufagtsllfynwqhdngldkdrkq",
+				Model:  "owner/model",
+				Status: replicate.Succeeded,
+				Output: "Hello, world!",
+			}
+			json.NewEncoder(w).Encode(prediction)
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	input := replicate.PredictionInput{"prompt": "Hello"}
+	output, err := client.Run(ctx, "owner/model", input, nil)
+
+	require.NoError(t, err)
+	assert.NotNil(t, output)
+	assert.Equal(t, "Hello, world!", output)
+}
+
+func TestRunWithInputFiles(t *testing.T) {
+	var uploads []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files":
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			require.NoError(t, err)
+			part, err := multipart.NewReader(r.Body, params["boundary"]).NextPart()
+			require.NoError(t, err)
+			content, err := io.ReadAll(part)
+			require.NoError(t, err)
+
+			id := fmt.Sprintf("file-%d", len(uploads))
+			uploads = append(uploads, part.FileName()+":"+string(content))
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(replicate.File{ID: id, URLs: map[string]string{"get": "https://api.replicate.com/v1/files/" + id}})
+		case "/models/owner/model/predictions":
+			assert.Equal(t, http.MethodPost, r.Method)
+
+			var requestBody map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+			assert.Equal(t, map[string]interface{}{
+				"image":  "https://api.replicate.com/v1/files/file-0",
+				"mask":   "https://api.replicate.com/v1/files/file-1",
+				"prompt": "a cat",
+			}, requestBody["input"])
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(replicate.Prediction{
+				ID:     "ndufagtsllfynwqhdngldkdrkq",
+				Status: replicate.Succeeded,
+				Output: "https://replicate.delivery/output.png",
+			})
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	imagePath := filepath.Join(t.TempDir(), "image.png")
+	require.NoError(t, os.WriteFile(imagePath, []byte("image data"), 0o644))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.PredictionInput{
+		"image":  imagePath,
+		"mask":   replicate.FileRef{Reader: strings.NewReader("mask data"), Filename: "mask.png"},
+		"prompt": "a cat",
+	}
+	output, err := client.RunWithOptions(ctx, "owner/model", input, nil,
+		replicate.WithBlockUntilDone(), replicate.WithInputFiles("image"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://replicate.delivery/output.png", output)
+	assert.Equal(t, []string{"image.png:image data", "mask.png:mask data"}, uploads)
+}
+
+func TestRunReturningModelError(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions":
+			assert.Equal(t, http.MethodPost, r.Method)
+			prediction := replicate.Prediction{
+				ID:      "fynndufawqhdngldkgtslldrkq",
+				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:  replicate.Starting,
+			}
+			json.NewEncoder(w).Encode(prediction)
+		case "/predictions/fynndufawqhdngldkgtslldrkq":
+			assert.Equal(t, http.MethodGet, r.Method)
+
+			logs := "Could not say hello"
+			prediction := replicate.Prediction{
+				ID:      "fynndufawqhdngldkgtslldrkq",
+				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:  replicate.Failed,
+				Logs:    &logs,
+				Error:   "Model execution failed",
+			}
+			json.NewEncoder(w).Encode(prediction)
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	input := replicate.PredictionInput{"prompt": "Hello"}
+	_, err = client.Run(ctx, "replicate/hello-world:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil)
+
+	require.Error(t, err)
+	modelErr, ok := err.(*replicate.ModelError)
+	require.True(t, ok, "Expected error to be of type *replicate.ModelError")
+	assert.Equal(t, "model error: Model execution failed", modelErr.Error())
+	assert.Equal(t, "fynndufawqhdngldkgtslldrkq", modelErr.Prediction.ID)
+	assert.Equal(t, replicate.Failed, modelErr.Prediction.Status)
+	assert.Equal(t, "Model execution failed", modelErr.Prediction.Error)
+	assert.Equal(t, "Could not say hello", *modelErr.Prediction.Logs)
+}
+
+func TestPredictionError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		body        string
+		wantMessage string
+		wantDetails map[string]interface{}
+	}{
+		{
+			name:        "No error",
+			body:        `{"id": "ufawqhfynnddngldkgtslldrkq", "status": "succeeded", "error": null}`,
+			wantMessage: "",
+		},
+		{
+			name:        "String error",
+			body:        `{"id": "ufawqhfynnddngldkgtslldrkq", "status": "failed", "error": "Model execution failed"}`,
+			wantMessage: "Model execution failed",
+		},
+		{
+			name:        "Structured error",
+			body:        `{"id": "ufawqhfynnddngldkgtslldrkq", "status": "failed", "error": {"message": "CUDA out of memory", "code": "E1001"}}`,
+			wantMessage: "CUDA out of memory",
+			wantDetails: map[string]interface{}{"message": "CUDA out of memory", "code": "E1001"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var prediction replicate.Prediction
+			require.NoError(t, json.Unmarshal([]byte(tc.body), &prediction))
+
+			assert.Equal(t, tc.wantMessage, prediction.ErrorString())
+			if tc.wantMessage == "" {
+				assert.Nil(t, prediction.Error)
+				assert.Nil(t, prediction.PredictionError())
+				return
+			}
+
+			// The raw error value is preserved for backwards compatibility
+			assert.NotNil(t, prediction.Error)
+
+			predictionError := prediction.PredictionError()
+			require.NotNil(t, predictionError)
+			assert.Equal(t, tc.wantMessage, predictionError.Message)
+			assert.Equal(t, tc.wantDetails, predictionError.Details)
+
+			modelErr := &replicate.ModelError{Prediction: &prediction}
+			assert.Equal(t, "model error: "+tc.wantMessage, modelErr.Error())
+
+			// The typed error follows changes to the raw error value
+			prediction.Error = "Prediction was canceled"
+			assert.Equal(t, "Prediction was canceled", prediction.PredictionError().Message)
+		})
+	}
+}
+
+func TestCreateTraining(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/models/owner/model/versions/632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532/trainings", r.URL.Path)
+
+		training := &replicate.Training{
+			ID:        "zz4ibbonubfz7carwiefibzgga",
+			Model:     "replicate/hello-world",
+			Version:   "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
+			Status:    replicate.Starting,
+			CreatedAt: "2023-03-28T21:47:58.566434Z",
+			URLs: map[string]string{
+				"get":    "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga",
+				"cancel": "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga/cancel",
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		body, _ := json.Marshal(training)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.TrainingInput{"text": "Alice"}
+	destination := "owner/new-model"
+	webhook := replicate.Webhook{
+		URL:    "https://example.com/webhook",
+		Events: []replicate.WebhookEventType{"start", "completed"},
+	}
+	training, err := client.CreateTraining(ctx, "owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", destination, input, &webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
+	assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", training.Version)
+	assert.Equal(t, replicate.Starting, training.Status)
+	assert.Equal(t, "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga", training.URLs["get"])
+	assert.Equal(t, "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga/cancel", training.URLs["cancel"])
+}
+
+func TestCreateTrainingWithOptions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/models/owner/model/versions/632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532/trainings", r.URL.Path)
+
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", body["version"])
+		assert.Equal(t, "owner/new-model", body["destination"])
+		assert.Equal(t, map[string]interface{}{"text": "Alice"}, body["input"])
+		assert.Equal(t, "https://example.com/webhook", body["webhook"])
+		assert.Equal(t, []interface{}{"completed"}, body["webhook_events_filter"])
+
+		training := &replicate.Training{
+			ID:      "zz4ibbonubfz7carwiefibzgga",
+			Version: "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
+			Status:  replicate.Starting,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(training)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	training, err := client.CreateTrainingWithOptions(ctx,
+		replicate.WithTrainingVersion("owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532"),
+		replicate.WithTrainingDestination("owner/new-model"),
+		replicate.WithTrainingInput(replicate.TrainingInput{"text": "Alice"}),
+		replicate.WithTrainingWebhook(&replicate.Webhook{
+			URL:    "https://example.com/webhook",
+			Events: []replicate.WebhookEventType{replicate.WebhookEventCompleted},
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
+	assert.Equal(t, replicate.Starting, training.Status)
+
+	_, err = client.CreateTrainingWithOptions(ctx, replicate.WithTrainingDestination("owner/new-model"))
+	assert.ErrorContains(t, err, "WithTrainingVersion")
+
+	_, err = client.CreateTrainingWithOptions(ctx, replicate.WithTrainingVersion("owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532"))
+	assert.ErrorContains(t, err, "WithTrainingDestination")
+}
+
+func TestGetTraining(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/trainings/zz4ibbonubfz7carwiefibzgga", r.URL.Path)
+
+		training := &replicate.Training{
+			ID:        "zz4ibbonubfz7carwiefibzgga",
+			Model:     "replicate/hello-world",
+			Version:   "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
+			Status:    replicate.Succeeded,
+			CreatedAt: "2023-03-28T21:47:58.566434Z",
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(training)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	training, err := client.GetTraining(ctx, "zz4ibbonubfz7carwiefibzgga")
+	assert.NoError(t, err)
+	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
+	assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", training.Version)
+	assert.Equal(t, replicate.Succeeded, training.Status)
+}
+
+func TestCancelTraining(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/trainings/zz4ibbonubfz7carwiefibzgga/cancel", r.URL.Path)
+
+		training := &replicate.Training{
+			ID:        "zz4ibbonubfz7carwiefibzgga",
+			Model:     "replicate/hello-world",
+			Version:   "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
+			Status:    replicate.Canceled,
+			CreatedAt: "2023-03-28T21:47:58.566434Z",
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(training)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	training, err := client.CancelTraining(ctx, "zz4ibbonubfz7carwiefibzgga")
+	assert.NoError(t, err)
+	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
+	assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", training.Version)
+	assert.Equal(t, replicate.Canceled, training.Status)
+}
+
+func TestWaitForTraining(t *testing.T) {
+	statuses := []replicate.Status{replicate.Starting, replicate.Processing, replicate.Succeeded}
+
+	i := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/trainings/zz4ibbonubfz7carwiefibzgga", r.URL.Path)
+
+		training := &replicate.Training{
+			ID:      "zz4ibbonubfz7carwiefibzgga",
+			Version: "4a056052b8b98f6db8d011a450abbcd09a408ec9280c29f22d3538af1099646a",
+			Status:  statuses[i],
+		}
+
+		if statuses[i] == replicate.Succeeded {
+			training.Output = map[string]interface{}{"weights": "https://example.com/weights.tar"}
+		}
+
+		if i < len(statuses)-1 {
+			i++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(training)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("succeeds", func(t *testing.T) {
+		training := &replicate.Training{
+			ID:     "zz4ibbonubfz7carwiefibzgga",
+			Status: replicate.Starting,
+		}
+
+		err := client.WaitForTraining(ctx, training, replicate.WithPollingInterval(1*time.Nanosecond))
+		require.NoError(t, err)
+		assert.Equal(t, replicate.Succeeded, training.Status)
+		assert.Equal(t, map[string]interface{}{"weights": "https://example.com/weights.tar"}, training.Output)
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		i = 0
+
+		training := &replicate.Training{
+			ID:     "zz4ibbonubfz7carwiefibzgga",
+			Status: replicate.Starting,
+		}
+
+		err := client.WaitForTraining(ctx, training,
+			replicate.WithPollingInterval(1*time.Nanosecond),
+			replicate.WithMaxAttempts(1),
+		)
+		require.ErrorIs(t, err, replicate.ErrWaitTimeout)
+		assert.Contains(t, err.Error(), "training zz4ibbonubfz7carwiefibzgga")
+		assert.Equal(t, replicate.Starting, training.Status)
+	})
+}
+
+func TestListTrainings(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/trainings", r.URL.Path)
+
+		response := &replicate.Page[replicate.Training]{
+			Previous: nil,
+			Next:     nil,
+			Results: []replicate.Training{
+				{ID: "ufawqhfynnddngldkgtslldrkq"},
+				{ID: "rrr4z55ocneqzikepnug6xezpe"},
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(response)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	page, err := client.ListTrainings(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, page.Results, 2)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", page.Results[0].ID)
+	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", page.Results[1].ID)
+}
+
+func TestListHardware(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/hardware", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		response := []replicate.Hardware{
+			{Name: "CPU", SKU: "cpu"},
+			{Name: "Nvidia T4 GPU", SKU: "gpu-t4"},
+			{Name: "Nvidia A40 GPU", SKU: "gpu-a40-small"},
+			{Name: "Nvidia A40 (Large) GPU", SKU: "gpu-a40-large"},
+		}
+
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseBytes)
 	}))
 	defer mockServer.Close()
 
@@ -1155,42 +4305,108 @@ func TestRunWithVersionlessModel(t *testing.T) {
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
 	)
+	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	ctx := context.Background()
-	input := replicate.PredictionInput{"prompt": "Hello"}
-	output, err := client.Run(ctx, "owner/model", input, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hardwareList, err := client.ListHardware(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 4, len(*hardwareList))
+
+	assert.Equal(t, "CPU", (*hardwareList)[0].Name)
+	assert.Equal(t, "cpu", (*hardwareList)[0].SKU)
+}
+
+func TestValidateHardware(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/hardware", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		response := []replicate.Hardware{
+			{Name: "CPU", SKU: "cpu"},
+			{Name: "Nvidia T4 GPU", SKU: "gpu-t4"},
+			{Name: "Nvidia B200 GPU", SKU: "gpu-b200"},
+		}
+
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
 
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseBytes)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
 	require.NoError(t, err)
-	assert.NotNil(t, output)
-	assert.Equal(t, "Hello, world!", output)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, client.ValidateHardware(ctx, replicate.HardwareGPUT4))
+	assert.NoError(t, client.ValidateHardware(ctx, "gpu-b200"))
+
+	// HardwareSKU values and strings are interchangeable
+	var sku replicate.HardwareSKU = "gpu-b200"
+	options := replicate.CreateDeploymentOptions{Hardware: replicate.HardwareGPUT4}
+	options.Hardware = sku
+	assert.NoError(t, client.ValidateHardware(ctx, options.Hardware))
+
+	err = client.ValidateHardware(ctx, "gpu-t5")
+	assert.ErrorIs(t, err, replicate.ErrUnknownHardware)
+	assert.ErrorContains(t, err, `"gpu-t5"`)
 }
 
-func TestRunReturningModelError(t *testing.T) {
+func TestAutomaticallyRetryGetRequests(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusOK}
+
+	i := 0
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.URL.Path {
-		case "/predictions":
-			assert.Equal(t, http.MethodPost, r.Method)
-			prediction := replicate.Prediction{
-				ID:      "fynndufawqhdngldkgtslldrkq",
-				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:  replicate.Starting,
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		status := statuses[i]
+		i++
+
+		if status == http.StatusOK {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+
+			prediction := &replicate.Prediction{
+				ID:        "ufawqhfynnddngldkgtslldrkq",
+				Model:     "replicate/hello-world",
+				Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:    replicate.Succeeded,
+				Input:     replicate.PredictionInput{"text": "Alice"},
+				Output:    map[string]interface{}{"text": "Hello, Alice"},
+				CreatedAt: "2022-04-26T22:13:06.224088Z",
+				URLs: map[string]string{
+					"get":    "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
+					"cancel": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel",
+				},
 			}
-			json.NewEncoder(w).Encode(prediction)
-		case "/predictions/fynndufawqhdngldkgtslldrkq":
-			assert.Equal(t, http.MethodGet, r.Method)
 
-			logs := "Could not say hello"
-			prediction := replicate.Prediction{
-				ID:      "fynndufawqhdngldkgtslldrkq",
-				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:  replicate.Failed,
-				Logs:    &logs,
-				Error:   "Model execution failed",
+			body, _ := json.Marshal(prediction)
+			w.Write(body)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+
+			err := replicate.APIError{
+				Detail: http.StatusText(status),
 			}
-			json.NewEncoder(w).Encode(prediction)
-		default:
-			t.Fatalf("Unexpected request to %s", r.URL.Path)
+			body, _ := json.Marshal(err)
+			w.Write(body)
 		}
 	}))
 	defer mockServer.Close()
@@ -1199,42 +4415,214 @@ func TestRunReturningModelError(t *testing.T) {
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
 	)
+	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	ctx := context.Background()
-	input := replicate.PredictionInput{"prompt": "Hello"}
-	_, err = client.Run(ctx, "replicate/hello-world:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	require.Error(t, err)
-	modelErr, ok := err.(*replicate.ModelError)
-	require.True(t, ok, "Expected error to be of type *replicate.ModelError")
-	assert.Equal(t, "model error: Model execution failed", modelErr.Error())
-	assert.Equal(t, "fynndufawqhdngldkgtslldrkq", modelErr.Prediction.ID)
-	assert.Equal(t, replicate.Failed, modelErr.Prediction.Status)
-	assert.Equal(t, "Model execution failed", modelErr.Prediction.Error)
-	assert.Equal(t, "Could not say hello", *modelErr.Prediction.Logs)
+	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+	assert.NoError(t, err)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
 }
 
-func TestCreateTraining(t *testing.T) {
+func TestRetryAttemptCount(t *testing.T) {
+	testCases := []struct {
+		maxRetries   int
+		wantRequests int
+	}{
+		{maxRetries: 0, wantRequests: 1},
+		{maxRetries: 1, wantRequests: 1},
+		{maxRetries: 3, wantRequests: 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(tc.maxRetries), func(t *testing.T) {
+			requests := 0
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer mockServer.Close()
+
+			const delay = 200 * time.Millisecond
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+				replicate.WithRetryPolicy(tc.maxRetries, &replicate.ConstantBackoff{Base: delay}),
+			)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			start := time.Now()
+			_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+			elapsed := time.Since(start)
+
+			apiError := &replicate.APIError{}
+			require.ErrorAs(t, err, &apiError)
+			assert.Equal(t, http.StatusServiceUnavailable, apiError.Status)
+			assert.Equal(t, tc.wantRequests, requests)
+
+			// There's a delay between requests, but not after the last one
+			waits := time.Duration(tc.wantRequests-1) * delay
+			assert.GreaterOrEqual(t, elapsed, waits)
+			assert.Less(t, elapsed, waits+delay)
+		})
+	}
+}
+
+func TestRetryableStatusCodesAndMethods(t *testing.T) {
+	var statuses []int
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[0]
+		statuses = statuses[1:]
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			json.NewEncoder(w).Encode(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq"})
+			return
+		}
+		json.NewEncoder(w).Encode(replicate.APIError{Detail: http.StatusText(status)})
+	}))
+	defer mockServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newClient := func(opts ...replicate.ClientOption) *replicate.Client {
+		opts = append([]replicate.ClientOption{
+			replicate.WithToken("test-token"),
+			replicate.WithBaseURL(mockServer.URL),
+		}, opts...)
+		client, err := replicate.NewClient(opts...)
+		require.NoError(t, err)
+		return client
+	}
+
+	t.Run("default", func(t *testing.T) {
+		statuses = []int{http.StatusRequestTimeout, http.StatusOK}
+
+		_, err := newClient().GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+		apiErr := &replicate.APIError{}
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusRequestTimeout, apiErr.Status)
+	})
+
+	t.Run("408 then 200", func(t *testing.T) {
+		statuses = []int{http.StatusRequestTimeout, http.StatusOK}
+
+		client := newClient(replicate.WithRetryableStatusCodes(http.StatusRequestTimeout, http.StatusConflict))
+		prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+		require.NoError(t, err)
+		assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+		assert.Empty(t, statuses)
+	})
+
+	t.Run("method not retried", func(t *testing.T) {
+		statuses = []int{http.StatusConflict, http.StatusOK}
+
+		client := newClient(replicate.WithRetryableStatusCodes(http.StatusConflict))
+		_, err := client.CreatePrediction(ctx, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{}, nil, false)
+		apiErr := &replicate.APIError{}
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusConflict, apiErr.Status)
+	})
+
+	t.Run("retry method", func(t *testing.T) {
+		statuses = []int{http.StatusConflict, http.StatusOK}
+
+		client := newClient(
+			replicate.WithRetryableStatusCodes(http.StatusConflict),
+			replicate.WithRetryMethods(http.MethodPost),
+		)
+		_, err := client.CreatePrediction(ctx, "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{}, nil, false)
+		require.NoError(t, err)
+		assert.Empty(t, statuses)
+	})
+
+	t.Run("invalid status code", func(t *testing.T) {
+		_, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithRetryableStatusCodes(1000),
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestAutomaticallyRetryPostRequests(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusInternalServerError}
+
+	i := 0
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
-		assert.Equal(t, "/models/owner/model/versions/632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532/trainings", r.URL.Path)
 
-		training := &replicate.Training{
-			ID:        "zz4ibbonubfz7carwiefibzgga",
-			Model:     "replicate/hello-world",
-			Version:   "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
-			Status:    replicate.Starting,
-			CreatedAt: "2023-03-28T21:47:58.566434Z",
-			URLs: map[string]string{
-				"get":    "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga",
-				"cancel": "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga/cancel",
-			},
+		var requestBody map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+		assert.Equal(t, map[string]interface{}{"text": "Alice"}, requestBody["input"])
+
+		status := statuses[i]
+		i++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+
+		err := replicate.APIError{
+			Detail: http.StatusText(status),
+		}
+		body, _ := json.Marshal(err)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	input := replicate.PredictionInput{"text": "Alice"}
+	webhook := replicate.Webhook{
+		URL:    "https://example.com/webhook",
+		Events: []replicate.WebhookEventType{"start", "completed"},
+	}
+	version := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+	_, err = client.CreatePrediction(ctx, version, input, &webhook, true)
+
+	assert.ErrorContains(t, err, http.StatusText(http.StatusInternalServerError))
+}
+
+func TestCreatePredictionWithIdempotencyKey(t *testing.T) {
+	statuses := []int{http.StatusTooManyRequests, http.StatusCreated}
+
+	i := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/predictions", r.URL.Path)
+		assert.Equal(t, "6a4c2d0e-ec36-4b1c-a9a5-fb7a9c1e0b25", r.Header.Get("Idempotency-Key"))
+
+		status := statuses[i]
+		i++
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(status)
+
+		if status != http.StatusCreated {
+			body, _ := json.Marshal(replicate.APIError{Detail: http.StatusText(status)})
+			w.Write(body)
+			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		body, _ := json.Marshal(training)
+		body, _ := json.Marshal(replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Starting})
 		w.Write(body)
 	}))
 	defer mockServer.Close()
@@ -1249,41 +4637,32 @@ func TestCreateTraining(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	input := replicate.TrainingInput{"text": "Alice"}
-	destination := "owner/new-model"
-	webhook := replicate.Webhook{
-		URL:    "https://example.com/webhook",
-		Events: []replicate.WebhookEventType{"start", "completed"},
-	}
-	training, err := client.CreateTraining(ctx, "owner", "model", "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", destination, input, &webhook)
-	if err != nil {
-		t.Fatal(err)
-	}
+	input := replicate.PredictionInput{"text": "Alice"}
+	version := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
+	prediction, err := client.CreatePredictionWithOptions(ctx,
+		replicate.WithPredictionVersion(version),
+		replicate.WithPredictionInput(input),
+		replicate.WithPredictionIdempotencyKey("6a4c2d0e-ec36-4b1c-a9a5-fb7a9c1e0b25"),
+	)
+	require.NoError(t, err)
 
-	assert.NoError(t, err)
-	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
-	assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", training.Version)
-	assert.Equal(t, replicate.Starting, training.Status)
-	assert.Equal(t, "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga", training.URLs["get"])
-	assert.Equal(t, "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga/cancel", training.URLs["cancel"])
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	assert.Equal(t, 2, i)
 }
 
-func TestGetTraining(t *testing.T) {
+func TestAPIErrorRetryAfter(t *testing.T) {
+	requests := 0
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodGet, r.Method)
-		assert.Equal(t, "/trainings/zz4ibbonubfz7carwiefibzgga", r.URL.Path)
-
-		training := &replicate.Training{
-			ID:        "zz4ibbonubfz7carwiefibzgga",
-			Model:     "replicate/hello-world",
-			Version:   "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
-			Status:    replicate.Succeeded,
-			CreatedAt: "2023-03-28T21:47:58.566434Z",
-		}
+		requests++
 
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(training)
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+
+		err := replicate.APIError{
+			Detail: http.StatusText(http.StatusTooManyRequests),
+		}
+		body, _ := json.Marshal(err)
 		w.Write(body)
 	}))
 	defer mockServer.Close()
@@ -1291,6 +4670,7 @@ func TestGetTraining(t *testing.T) {
 	client, err := replicate.NewClient(
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
+		replicate.WithRetryPolicy(0, &replicate.ConstantBackoff{}),
 	)
 	require.NotNil(t, client)
 	require.NoError(t, err)
@@ -1298,30 +4678,27 @@ func TestGetTraining(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	training, err := client.GetTraining(ctx, "zz4ibbonubfz7carwiefibzgga")
-	assert.NoError(t, err)
-	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
-	assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", training.Version)
-	assert.Equal(t, replicate.Succeeded, training.Status)
-}
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
 
-func TestCancelTraining(t *testing.T) {
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodPost, r.Method)
-		assert.Equal(t, "/trainings/zz4ibbonubfz7carwiefibzgga/cancel", r.URL.Path)
+	apiErr := &replicate.APIError{}
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.Status)
+	assert.Equal(t, 30*time.Second, apiErr.RetryAfter)
+	assert.True(t, apiErr.IsRetryable())
+	assert.Equal(t, 1, requests)
 
-		training := &replicate.Training{
-			ID:        "zz4ibbonubfz7carwiefibzgga",
-			Model:     "replicate/hello-world",
-			Version:   "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532",
-			Status:    replicate.Canceled,
-			CreatedAt: "2023-03-28T21:47:58.566434Z",
-		}
+	assert.True(t, (&replicate.APIError{Status: http.StatusServiceUnavailable}).IsRetryable())
+	assert.False(t, (&replicate.APIError{Status: http.StatusUnprocessableEntity}).IsRetryable())
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(training)
-		w.Write(body)
+func TestAPIErrorWithHTMLBody(t *testing.T) {
+	body := "<html>\n<head><title>400 Bad Request</title></head>\n<body>\n<center><h1>400 Bad Request</h1></center>\n<hr><center>cloudflare</center>\n" +
+		strings.Repeat("<!-- padding -->\n", 20) + "</body>\n</html>\n"
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(body))
 	}))
 	defer mockServer.Close()
 
@@ -1335,31 +4712,34 @@ func TestCancelTraining(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	training, err := client.CancelTraining(ctx, "zz4ibbonubfz7carwiefibzgga")
-	assert.NoError(t, err)
-	assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", training.ID)
-	assert.Equal(t, "632231d0d49d34d5c4633bd838aee3d81d936e59a886fbf28524702003b4c532", training.Version)
-	assert.Equal(t, replicate.Canceled, training.Status)
+	_, err = client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
+
+	apiErr := &replicate.APIError{}
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.Status)
+	assert.Equal(t, body, apiErr.RawBody)
+	assert.Equal(t, "non-JSON error response (status 400)", apiErr.Detail)
+
+	assert.ErrorContains(t, err, "non-JSON error response (status 400): <html> <head><title>400 Bad Request</title></head>")
+	assert.True(t, strings.HasSuffix(apiErr.Error(), "..."))
+	assert.NotContains(t, apiErr.Error(), "</html>")
 }
 
-func TestListTrainings(t *testing.T) {
+func TestRequestError(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodGet, r.Method)
-		assert.Equal(t, "/trainings", r.URL.Path)
-
-		response := &replicate.Page[replicate.Training]{
-			Previous: nil,
-			Next:     nil,
-			Results: []replicate.Training{
-				{ID: "ufawqhfynnddngldkgtslldrkq"},
-				{ID: "rrr4z55ocneqzikepnug6xezpe"},
-			},
+		switch r.URL.Path {
+		case "/predictions/missing":
+			w.Header().Set("Replicate-Request-ID", "req_8f3c2a")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(replicate.APIError{Detail: "Not found."})
+		case "/predictions/unidentified":
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(replicate.APIError{Detail: "Not found."})
+		default:
+			w.Header().Set("X-Request-ID", "req_1b7d9e")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("not json"))
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		body, _ := json.Marshal(response)
-		w.Write(body)
 	}))
 	defer mockServer.Close()
 
@@ -1373,32 +4753,56 @@ func TestListTrainings(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	page, err := client.ListTrainings(ctx)
-	assert.NoError(t, err)
-	assert.Len(t, page.Results, 2)
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", page.Results[0].ID)
-	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", page.Results[1].ID)
-}
+	_, err = client.GetPrediction(ctx, "missing")
+	requestErr := &replicate.RequestError{}
+	require.ErrorAs(t, err, &requestErr)
+	assert.Equal(t, "req_8f3c2a", requestErr.RequestID)
+	assert.ErrorContains(t, err, "Not found. (request ID: req_8f3c2a)")
 
-func TestListHardware(t *testing.T) {
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/hardware", r.URL.Path)
-		assert.Equal(t, http.MethodGet, r.Method)
+	apiErr := &replicate.APIError{}
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
 
-		response := []replicate.Hardware{
-			{Name: "CPU", SKU: "cpu"},
-			{Name: "Nvidia T4 GPU", SKU: "gpu-t4"},
-			{Name: "Nvidia A40 GPU", SKU: "gpu-a40-small"},
-			{Name: "Nvidia A40 (Large) GPU", SKU: "gpu-a40-large"},
-		}
+	_, err = client.GetPrediction(ctx, "malformed")
+	require.ErrorAs(t, err, &requestErr)
+	assert.Equal(t, "req_1b7d9e", requestErr.RequestID)
+	assert.ErrorContains(t, err, "failed to unmarshal response")
 
-		responseBytes, err := json.Marshal(response)
-		if err != nil {
-			t.Fatal(err)
-		}
+	_, err = client.GetPrediction(ctx, "unidentified")
+	assert.False(t, errors.As(err, &requestErr))
+	assert.ErrorAs(t, err, &apiErr)
+}
 
-		w.WriteHeader(http.StatusOK)
-		w.Write(responseBytes)
+func TestRunWithOptions(t *testing.T) {
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions":
+			assert.Equal(t, http.MethodPost, r.Method)
+			prediction := replicate.Prediction{
+				ID:      "gtsllfynndufawqhdngldkdrkq",
+				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:  replicate.Starting,
+			}
+			json.NewEncoder(w).Encode(prediction)
+		case "/predictions/gtsllfynndufawqhdngldkdrkq":
+			assert.Equal(t, http.MethodGet, r.Method)
+			prediction := replicate.Prediction{
+				ID:      "gtsllfynndufawqhdngldkdrkq",
+				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Status:  replicate.Succeeded,
+				Output: map[string]interface{}{
+					"image": mockServer.URL + "/output.png",
+					"text":  "Hello, world!",
+				},
+			}
+			json.NewEncoder(w).Encode(prediction)
+		case "/output.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("mock image data"))
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
 	}))
 	defer mockServer.Close()
 
@@ -1406,63 +4810,64 @@ func TestListHardware(t *testing.T) {
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
 	)
-	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	ctx := context.Background()
+	input := replicate.PredictionInput{"prompt": "A test image"}
 
-	hardwareList, err := client.ListHardware(ctx)
-	if err != nil {
-		t.Fatal(err)
-	}
+	// Test with WithFileOutput option
+	output, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, replicate.WithFileOutput())
 
-	assert.Equal(t, 4, len(*hardwareList))
+	require.NoError(t, err)
+	assert.NotNil(t, output)
 
-	assert.Equal(t, "CPU", (*hardwareList)[0].Name)
-	assert.Equal(t, "cpu", (*hardwareList)[0].SKU)
-}
+	// Check if the image output is transformed to io.ReadCloser
+	imageOutput, ok := output.(map[string]interface{})["image"].(io.ReadCloser)
+	require.True(t, ok, "Expected image output to be io.ReadCloser")
 
-func TestAutomaticallyRetryGetRequests(t *testing.T) {
-	statuses := []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusOK}
+	imageData, err := io.ReadAll(imageOutput)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("mock image data"), imageData)
 
-	i := 0
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodGet, r.Method)
+	// Check if the text output remains unchanged
+	textOutput, ok := output.(map[string]interface{})["text"].(string)
+	require.True(t, ok, "Expected text output to be string")
+	assert.Equal(t, "Hello, world!", textOutput)
 
-		status := statuses[i]
-		i++
+	// Test without WithFileOutput option
+	outputWithoutFileOption, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil)
 
-		if status == http.StatusOK {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(status)
+	require.NoError(t, err)
+	assert.NotNil(t, outputWithoutFileOption)
 
-			prediction := &replicate.Prediction{
-				ID:        "ufawqhfynnddngldkgtslldrkq",
-				Model:     "replicate/hello-world",
-				Version:   "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:    replicate.Succeeded,
-				Input:     replicate.PredictionInput{"text": "Alice"},
-				Output:    map[string]interface{}{"text": "Hello, Alice"},
-				CreatedAt: "2022-04-26T22:13:06.224088Z",
-				URLs: map[string]string{
-					"get":    "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
-					"cancel": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq/cancel",
-				},
-			}
+	// Check if the image output remains a URL string
+	imageOutputURL, ok := outputWithoutFileOption.(map[string]interface{})["image"].(string)
+	require.True(t, ok, "Expected image output to be string")
+	assert.Equal(t, mockServer.URL+"/output.png", imageOutputURL)
+}
 
-			body, _ := json.Marshal(prediction)
-			w.Write(body)
-		} else {
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("Retry-After", "0")
-			w.WriteHeader(status)
+func TestRunWithFileOutputDataURI(t *testing.T) {
+	content := strings.Repeat("mock image data ", 1024)
+	dataURI := "data:image/png;base64," + base64.StdEncoding.EncodeToString([]byte(content))
 
-			err := replicate.APIError{
-				Detail: http.StatusText(status),
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/predictions":
+			prediction := replicate.Prediction{
+				ID:     "gtsllfynndufawqhdngldkdrkq",
+				Status: replicate.Starting,
 			}
-			body, _ := json.Marshal(err)
-			w.Write(body)
+			json.NewEncoder(w).Encode(prediction)
+		case "/predictions/gtsllfynndufawqhdngldkdrkq":
+			prediction := replicate.Prediction{
+				ID:     "gtsllfynndufawqhdngldkdrkq",
+				Status: replicate.Succeeded,
+				Output: []interface{}{dataURI, "data:text/plain,hello", "data:image/png;base64,not base64!"},
+			}
+			json.NewEncoder(w).Encode(prediction)
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
 		}
 	}))
 	defer mockServer.Close()
@@ -1471,36 +4876,74 @@ func TestAutomaticallyRetryGetRequests(t *testing.T) {
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
 	)
-	require.NotNil(t, client)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	prediction, err := client.GetPrediction(ctx, "ufawqhfynnddngldkgtslldrkq")
-	assert.NoError(t, err)
-	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", prediction.ID)
+	output, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", replicate.PredictionInput{}, nil, replicate.WithFileOutput())
+	require.NoError(t, err)
+
+	outputs, ok := output.([]interface{})
+	require.True(t, ok)
+	require.Len(t, outputs, 3)
+
+	image, ok := outputs[0].(*replicate.FileOutput)
+	require.True(t, ok)
+	assert.Equal(t, dataURI, image.URL)
+	data, err := io.ReadAll(image)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+
+	text, ok := outputs[1].(*replicate.FileOutput)
+	require.True(t, ok)
+	data, err = io.ReadAll(text)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// Invalid base64 is reported when the output is read
+	invalid, ok := outputs[2].(*replicate.FileOutput)
+	require.True(t, ok)
+	_, err = io.ReadAll(invalid)
+	assert.Error(t, err)
 }
 
-func TestAutomaticallyRetryPostRequests(t *testing.T) {
-	statuses := []int{http.StatusTooManyRequests, http.StatusInternalServerError}
+func TestDataURIRoundTrip(t *testing.T) {
+	image := []byte("\x89PNG\r\n\x1a\nmock image data")
+	imageURI := replicate.DataURIFromBytes("image/png", image)
+	assert.True(t, strings.HasPrefix(imageURI, "data:image/png;base64,"))
 
-	i := 0
-	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, http.MethodPost, r.Method)
+	textURI, err := replicate.DataURIFromReader("text/plain; charset=utf-8", strings.NewReader("hello, world"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(textURI, "data:text/plain;charset=utf-8;base64,"))
 
-		status := statuses[i]
-		i++
+	assert.True(t, strings.HasPrefix(replicate.DataURIFromBytes("", image), "data:application/octet-stream;base64,"))
+	assert.True(t, strings.HasPrefix(replicate.DataURIFromBytes("not a content type", image), "data:application/octet-stream;base64,"))
 
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Retry-After", "0")
-		w.WriteHeader(status)
-
-		err := replicate.APIError{
-			Detail: http.StatusText(status),
+		switch r.URL.Path {
+		case "/predictions":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{"image": imageURI, "text": textURI}, body["input"])
+
+			prediction := replicate.Prediction{
+				ID:     "gtsllfynndufawqhdngldkdrkq",
+				Status: replicate.Starting,
+			}
+			json.NewEncoder(w).Encode(prediction)
+		case "/predictions/gtsllfynndufawqhdngldkdrkq":
+			// Echo the inputs as outputs
+			prediction := replicate.Prediction{
+				ID:     "gtsllfynndufawqhdngldkdrkq",
+				Status: replicate.Succeeded,
+				Output: []interface{}{imageURI, textURI},
+			}
+			json.NewEncoder(w).Encode(prediction)
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
 		}
-		body, _ := json.Marshal(err)
-		w.Write(body)
 	}))
 	defer mockServer.Close()
 
@@ -1508,50 +4951,37 @@ func TestAutomaticallyRetryPostRequests(t *testing.T) {
 		replicate.WithToken("test-token"),
 		replicate.WithBaseURL(mockServer.URL),
 	)
-	require.NotNil(t, client)
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	input := replicate.PredictionInput{"text": "Alice"}
-	webhook := replicate.Webhook{
-		URL:    "https://example.com/webhook",
-		Events: []replicate.WebhookEventType{"start", "completed"},
-	}
-	version := "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"
-	_, err = client.CreatePrediction(ctx, version, input, &webhook, true)
+	input := replicate.PredictionInput{"image": imageURI, "text": textURI}
+	output, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, replicate.WithFileOutput())
+	require.NoError(t, err)
 
-	assert.ErrorContains(t, err, http.StatusText(http.StatusInternalServerError))
+	outputs, ok := output.([]interface{})
+	require.True(t, ok)
+	require.Len(t, outputs, 2)
+
+	for i, expected := range []string{string(image), "hello, world"} {
+		file, ok := outputs[i].(*replicate.FileOutput)
+		require.True(t, ok)
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(data))
+	}
 }
 
-func TestRunWithOptions(t *testing.T) {
-	var mockServer *httptest.Server
-	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func TestReadFileOutputRange(t *testing.T) {
+	content := "0123456789abcdefghij"
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
-		case "/predictions":
-			assert.Equal(t, http.MethodPost, r.Method)
-			prediction := replicate.Prediction{
-				ID:      "gtsllfynndufawqhdngldkdrkq",
-				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:  replicate.Starting,
-			}
-			json.NewEncoder(w).Encode(prediction)
-		case "/predictions/gtsllfynndufawqhdngldkdrkq":
-			assert.Equal(t, http.MethodGet, r.Method)
-			prediction := replicate.Prediction{
-				ID:      "gtsllfynndufawqhdngldkdrkq",
-				Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
-				Status:  replicate.Succeeded,
-				Output: map[string]interface{}{
-					"image": mockServer.URL + "/output.png",
-					"text":  "Hello, world!",
-				},
-			}
-			json.NewEncoder(w).Encode(prediction)
-		case "/output.png":
-			w.Header().Set("Content-Type", "image/png")
-			w.Write([]byte("mock image data"))
+		case "/output.mp4":
+			http.ServeContent(w, r, "output.mp4", time.Time{}, strings.NewReader(content))
+		case "/no-ranges.mp4":
+			w.Write([]byte(content))
 		default:
 			t.Fatalf("Unexpected request to %s", r.URL.Path)
 		}
@@ -1564,38 +4994,135 @@ func TestRunWithOptions(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	ctx := context.Background()
-	input := replicate.PredictionInput{"prompt": "A test image"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Test with WithFileOutput option
-	output, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, replicate.WithFileOutput())
+	output, err := client.ReadFileOutputRange(ctx, mockServer.URL+"/output.mp4", 10, -1)
+	require.NoError(t, err)
+	data, err := io.ReadAll(output)
+	require.NoError(t, err)
+	output.Close()
+	assert.Equal(t, "abcdefghij", string(data))
 
+	output, err = client.ReadFileOutputRange(ctx, mockServer.URL+"/output.mp4", 2, 4)
 	require.NoError(t, err)
-	assert.NotNil(t, output)
+	data, err = io.ReadAll(output)
+	require.NoError(t, err)
+	output.Close()
+	assert.Equal(t, "234", string(data))
 
-	// Check if the image output is transformed to io.ReadCloser
-	imageOutput, ok := output.(map[string]interface{})["image"].(io.ReadCloser)
-	require.True(t, ok, "Expected image output to be io.ReadCloser")
+	_, err = client.ReadFileOutputRange(ctx, mockServer.URL+"/no-ranges.mp4", 10, -1)
+	assert.ErrorIs(t, err, replicate.ErrRangeNotSupported)
 
-	imageData, err := io.ReadAll(imageOutput)
+	output, err = client.ReadFileOutputRange(ctx, mockServer.URL+"/no-ranges.mp4", 0, -1)
 	require.NoError(t, err)
-	assert.Equal(t, []byte("mock image data"), imageData)
+	data, err = io.ReadAll(output)
+	require.NoError(t, err)
+	output.Close()
+	assert.Equal(t, content, string(data))
 
-	// Check if the text output remains unchanged
-	textOutput, ok := output.(map[string]interface{})["text"].(string)
-	require.True(t, ok, "Expected text output to be string")
-	assert.Equal(t, "Hello, world!", textOutput)
+	_, err = client.ReadFileOutputRange(ctx, mockServer.URL+"/output.mp4", 5, 2)
+	assert.Error(t, err)
+}
 
-	// Test without WithFileOutput option
-	outputWithoutFileOption, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil)
+func TestFileOutputSave(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
+	body := &closeRecorder{Reader: strings.NewReader("mock image data")}
+	output := &replicate.FileOutput{ReadCloser: body, URL: "https://example.com/output.png"}
+
+	path := filepath.Join(t.TempDir(), "nested", "dir", "output.png")
+	n, err := output.Save(ctx, path)
 	require.NoError(t, err)
-	assert.NotNil(t, outputWithoutFileOption)
+	assert.Equal(t, int64(len("mock image data")), n)
+	assert.True(t, body.closed)
 
-	// Check if the image output remains a URL string
-	imageOutputURL, ok := outputWithoutFileOption.(map[string]interface{})["image"].(string)
-	require.True(t, ok, "Expected image output to be string")
-	assert.Equal(t, mockServer.URL+"/output.png", imageOutputURL)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "mock image data", string(data))
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		output := &replicate.FileOutput{ReadCloser: io.NopCloser(strings.NewReader("data")), URL: "https://example.com/output.png"}
+		_, err := output.Save(ctx, filepath.Join(t.TempDir(), "output.png"))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRunWithBlockUntilDoneTimeout(t *testing.T) {
+	testCases := []struct {
+		name        string
+		option      replicate.RunOption
+		wantPrefer  string
+		wantPolling bool
+	}{
+		{name: "Without timeout", option: replicate.WithBlockUntilDone(), wantPrefer: "wait"},
+		{name: "With timeout", option: replicate.WithBlockUntilDoneTimeout(10), wantPrefer: "wait=10"},
+		{name: "Falls back to polling", option: replicate.WithBlockUntilDoneTimeout(5), wantPrefer: "wait=5", wantPolling: true},
+		{name: "Clamped to maximum", option: replicate.WithBlockUntilDoneTimeout(300), wantPrefer: "wait=60"},
+		{name: "Clamped to minimum", option: replicate.WithBlockUntilDoneTimeout(0), wantPrefer: "wait=1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/predictions":
+					assert.Equal(t, http.MethodPost, r.Method)
+					assert.Equal(t, tc.wantPrefer, r.Header.Get("Prefer"))
+
+					prediction := replicate.Prediction{
+						ID:     "gtsllfynndufawqhdngldkdrkq",
+						Status: replicate.Succeeded,
+						Output: "Hello, world!",
+					}
+					if tc.wantPolling {
+						// The prediction hasn't finished within the timeout
+						prediction.Status = replicate.Processing
+						prediction.Output = nil
+					}
+					json.NewEncoder(w).Encode(prediction)
+				case "/predictions/gtsllfynndufawqhdngldkdrkq":
+					assert.Equal(t, http.MethodGet, r.Method)
+					prediction := replicate.Prediction{
+						ID:     "gtsllfynndufawqhdngldkdrkq",
+						Status: replicate.Succeeded,
+						Output: "Hello, world!",
+					}
+					json.NewEncoder(w).Encode(prediction)
+				default:
+					t.Fatalf("Unexpected request to %s", r.URL.Path)
+				}
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			)
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			input := replicate.PredictionInput{"prompt": "Hello"}
+			output, err := client.RunWithOptions(ctx, "owner/model:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", input, nil, tc.option)
+
+			require.NoError(t, err)
+			assert.Equal(t, "Hello, world!", output)
+		})
+	}
 }
 
 func TestStream(t *testing.T) {
@@ -1685,6 +5212,65 @@ func TestStream(t *testing.T) {
 	}
 }
 
+func TestStreamWithDeployment(t *testing.T) {
+	mockServer := httptest.NewUnstartedServer(nil)
+	mockServer.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/deployments/owner/name/predictions":
+			var requestBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&requestBody)
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]interface{}{"text": "Alice"}, requestBody["input"])
+			assert.Equal(t, true, requestBody["stream"])
+
+			response := replicate.Prediction{
+				ID:     "ufawqhfynnddngldkgtslldrkq",
+				Status: replicate.Starting,
+				URLs: map[string]string{
+					"stream": fmt.Sprintf("%s/predictions/ufawqhfynnddngldkgtslldrkq/stream", mockServer.URL),
+				},
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(response)
+		case r.Method == http.MethodGet && r.URL.Path == "/predictions/ufawqhfynnddngldkgtslldrkq/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "event: output\ndata: Hello\n\nevent: done\ndata: {}\n\n")
+		default:
+			t.Fatalf("Unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	mockServer.Start()
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sseChan, errChan := client.StreamWithDeployment(ctx, "owner", "name", replicate.PredictionInput{"text": "Alice"}, nil)
+
+	events := []replicate.SSEEvent{}
+	for event := range sseChan {
+		events = append(events, event)
+	}
+	for err := range errChan {
+		assert.NoError(t, err)
+	}
+
+	require.Len(t, events, 2)
+	assert.Equal(t, replicate.SSETypeOutput, events[0].Type)
+	assert.Equal(t, "Hello", events[0].Data)
+	assert.Equal(t, replicate.SSETypeDone, events[1].Type)
+}
+
 func TestCreateFile(t *testing.T) {
 	fileID := "file-id"
 	options := &replicate.CreateFileOptions{
@@ -1693,9 +5279,11 @@ func TestCreateFile(t *testing.T) {
 		Metadata:    map[string]string{"foo": "bar"},
 	}
 
+	var transferEncoding []string
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/files", r.URL.Path)
 		assert.Equal(t, http.MethodPost, r.Method)
+		transferEncoding = r.TransferEncoding
 
 		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 		if err != nil {
@@ -1782,6 +5370,16 @@ func TestCreateFile(t *testing.T) {
 		}
 		assertCreatedFile(t, fileID, file)
 	})
+
+	t.Run("CreateFileFromReader", func(t *testing.T) {
+		reader := io.MultiReader(strings.NewReader("Hello, "), strings.NewReader("world!"))
+		file, err := client.CreateFileFromReader(ctx, reader, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertCreatedFile(t, fileID, file)
+		assert.Equal(t, []string{"chunked"}, transferEncoding)
+	})
 }
 
 func assertCreatedFile(t *testing.T, fileID string, file *replicate.File) {
@@ -1853,6 +5451,59 @@ func TestListFiles(t *testing.T) {
 	assert.Equal(t, "https://api.replicate.com/v1/files/"+fileID, file.URLs["get"])
 }
 
+func TestListFilesWithOptions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/files", r.URL.Path)
+
+		query := r.URL.Query()
+		assert.Equal(t, "created_at", query.Get("ordering"))
+		assert.Equal(t, "inputs", query.Get("metadata.purpose"))
+		assert.Equal(t, "alice", query.Get("metadata.user"))
+
+		var response replicate.Page[replicate.File]
+		switch query.Get("cursor") {
+		case "":
+			next := "/files?" + query.Encode() + "&cursor=page2"
+			response = replicate.Page[replicate.File]{
+				Next:    &next,
+				Results: []replicate.File{{ID: "file1"}},
+			}
+		case "page2":
+			response = replicate.Page[replicate.File]{
+				Results: []replicate.File{{ID: "file2"}},
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListFilesWithOptions(ctx,
+		replicate.WithFilesCreatedAtOrder(true),
+		replicate.WithFilesMetadata("purpose", "inputs"),
+		replicate.WithFilesMetadata("user", "alice"),
+	)
+	require.NoError(t, err)
+
+	files, err := replicate.Collect(ctx, client, initialPage)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "file1", files[0].ID)
+	assert.Equal(t, "file2", files[1].ID)
+}
+
 func TestGetFile(t *testing.T) {
 	fileID := "file-id"
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1873,8 +5524,134 @@ func TestGetFile(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		w.WriteHeader(http.StatusOK)
-		w.Write(responseBytes)
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseBytes)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	file, err := client.GetFile(ctx, fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, fileID, file.ID)
+	assert.Equal(t, "hello.txt", file.Name)
+	assert.Equal(t, "text/plain", file.ContentType)
+	assert.Equal(t, 13, file.Size)
+	assert.Equal(t, "2022-04-26T22:13:06.224088Z", file.CreatedAt)
+	assert.Equal(t, "https://api.replicate.com/v1/files/"+fileID, file.URLs["get"])
+}
+
+func TestDownloadFile(t *testing.T) {
+	storageServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/presigned/hello.txt", r.URL.Path)
+		assert.Empty(t, r.Header.Get("Authorization"))
+		w.Write([]byte("Hello, storage!"))
+	}))
+	defer storageServer.Close()
+
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+
+		file := &replicate.File{ID: path.Base(r.URL.Path)}
+		switch r.URL.Path {
+		case "/files/api-file":
+			file.URLs = map[string]string{"get": mockServer.URL + "/files/api-file/download"}
+		case "/files/presigned-file":
+			file.URLs = map[string]string{"get": storageServer.URL + "/presigned/hello.txt"}
+		case "/files/missing-file":
+			file.URLs = map[string]string{"get": mockServer.URL + "/files/missing-file/download"}
+		case "/files/api-file/download":
+			w.Write([]byte("Hello, API!"))
+			return
+		case "/files/missing-file/download":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Not found"}`))
+			return
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(file)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("API URL", func(t *testing.T) {
+		body, err := client.DownloadFile(ctx, "api-file")
+		require.NoError(t, err)
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, API!", string(data))
+	})
+
+	t.Run("pre-signed URL", func(t *testing.T) {
+		body, err := client.DownloadFile(ctx, "presigned-file")
+		require.NoError(t, err)
+		defer body.Close()
+
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, storage!", string(data))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := client.DownloadFile(ctx, "missing-file")
+		apiErr := &replicate.APIError{}
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.Status)
+	})
+}
+
+func TestDownloadFileVerified(t *testing.T) {
+	content := []byte("Hello, world!")
+	checksum := sha256.Sum256(content)
+
+	var mockServer *httptest.Server
+	mockServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file := &replicate.File{ID: path.Base(r.URL.Path)}
+		switch r.URL.Path {
+		case "/files/complete", "/files/truncated":
+			file.Checksums = map[string]string{"sha256": hex.EncodeToString(checksum[:])}
+			file.URLs = map[string]string{"get": mockServer.URL + r.URL.Path + "/download"}
+		case "/files/unchecked":
+			file.URLs = map[string]string{"get": mockServer.URL + r.URL.Path + "/download"}
+		case "/files/complete/download":
+			w.Write(content)
+			return
+		case "/files/truncated/download":
+			w.Write(content[:5])
+			return
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(file)
 	}))
 	defer mockServer.Close()
 
@@ -1888,17 +5665,29 @@ func TestGetFile(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	file, err := client.GetFile(ctx, fileID)
-	if err != nil {
-		t.Fatal(err)
-	}
+	t.Run("complete", func(t *testing.T) {
+		body, err := client.DownloadFileVerified(ctx, "complete")
+		require.NoError(t, err)
+		defer body.Close()
 
-	assert.Equal(t, fileID, file.ID)
-	assert.Equal(t, "hello.txt", file.Name)
-	assert.Equal(t, "text/plain", file.ContentType)
-	assert.Equal(t, 13, file.Size)
-	assert.Equal(t, "2022-04-26T22:13:06.224088Z", file.CreatedAt)
-	assert.Equal(t, "https://api.replicate.com/v1/files/"+fileID, file.URLs["get"])
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+		assert.Equal(t, content, data)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		body, err := client.DownloadFileVerified(ctx, "truncated")
+		require.NoError(t, err)
+		defer body.Close()
+
+		_, err = io.ReadAll(body)
+		assert.ErrorIs(t, err, replicate.ErrChecksumMismatch)
+	})
+
+	t.Run("no checksum", func(t *testing.T) {
+		_, err := client.DownloadFileVerified(ctx, "unchecked")
+		assert.Error(t, err)
+	})
 }
 
 func TestDeleteFile(t *testing.T) {
@@ -1961,6 +5750,41 @@ func TestGetCurrentAccount(t *testing.T) {
 	assert.Equal(t, "https://github.com/replicate", account.GithubURL)
 }
 
+func TestGetAccount(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/account/acme", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		account := replicate.Account{
+			Type:     "organization",
+			Username: "acme",
+			Name:     "Acme, Inc.",
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		body, _ := json.Marshal(account)
+		w.Write(body)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	account, err := client.GetAccount(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, "organization", account.Type)
+	assert.Equal(t, "acme", account.Username)
+	assert.Equal(t, "Acme, Inc.", account.Name)
+}
+
 func TestGetDefaultWebhookSecret(t *testing.T) {
 	// This is a test secret and should not be used in production
 	testSecret := replicate.WebhookSigningSecret{
@@ -2018,6 +5842,222 @@ func TestValidateWebhook(t *testing.T) {
 	assert.Equal(t, body, string(bodyBytes))
 }
 
+func TestValidateWebhookWithSecrets(t *testing.T) {
+	// These are test secrets and should not be used in production
+	oldSecret := replicate.WebhookSigningSecret{
+		Key: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", // nolint:gosec
+	}
+	newSecret := replicate.WebhookSigningSecret{
+		Key: "whsec_" + base64.StdEncoding.EncodeToString([]byte("rotated-test-secret")), // nolint:gosec
+	}
+	otherSecret := replicate.WebhookSigningSecret{
+		Key: "whsec_" + base64.StdEncoding.EncodeToString([]byte("unrelated-test-secret")), // nolint:gosec
+	}
+
+	body := `{"id": "ufawqhfynnddngldkgtslldrkq", "status": "succeeded"}`
+
+	// Signed with the new secret during rotation
+	isValid, err := replicate.ValidateWebhookRequestWithSecrets(newSignedWebhookRequest(t, newSecret, body), oldSecret, newSecret)
+	require.NoError(t, err)
+	assert.True(t, isValid)
+
+	// Signed with the old secret during rotation
+	isValid, err = replicate.ValidateWebhookRequestWithSecrets(newSignedWebhookRequest(t, oldSecret, body), oldSecret, newSecret)
+	require.NoError(t, err)
+	assert.True(t, isValid)
+
+	// Signed with neither secret
+	isValid, err = replicate.ValidateWebhookRequestWithSecrets(newSignedWebhookRequest(t, otherSecret, body), oldSecret, newSecret)
+	require.NoError(t, err)
+	assert.False(t, isValid)
+
+	_, err = replicate.ValidateWebhookRequestWithSecrets(newSignedWebhookRequest(t, newSecret, body))
+	assert.Error(t, err)
+}
+
+func TestValidateWebhookWithTolerance(t *testing.T) {
+	// This is a test secret and should not be used in production
+	testSecret := replicate.WebhookSigningSecret{
+		Key: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", // nolint:gosec
+	}
+
+	// A request signed now is within the tolerance
+	isValid, err := replicate.ValidateWebhookRequestWithTolerance(newSignedWebhookRequest(t, testSecret, `{"id": "ufawqhfynnddngldkgtslldrkq"}`), testSecret, 5*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, isValid)
+
+	// The Svix test request was signed in 2021, so it's only valid without a tolerance
+	newOldRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "http://test.host/webhook", strings.NewReader(`{"test": 2432232314}`))
+		req.Header.Add("Webhook-ID", "msg_p5jXN8AQM9LWM0D4loKWxJek")
+		req.Header.Add("Webhook-Timestamp", "1614265330")
+		req.Header.Add("Webhook-Signature", "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE=")
+		return req
+	}
+
+	isValid, err = replicate.ValidateWebhookRequest(newOldRequest(), testSecret)
+	require.NoError(t, err)
+	assert.True(t, isValid)
+
+	isValid, err = replicate.ValidateWebhookRequestWithTolerance(newOldRequest(), testSecret, 5*time.Minute)
+	assert.ErrorIs(t, err, replicate.ErrWebhookTimestampOutOfTolerance)
+	assert.False(t, isValid)
+
+	// Timestamps in the future are also rejected
+	req := newOldRequest()
+	req.Header.Set("Webhook-Timestamp", fmt.Sprintf("%d", time.Now().Add(time.Hour).Unix()))
+	_, err = replicate.ValidateWebhookRequestWithTolerance(req, testSecret, 5*time.Minute)
+	assert.ErrorIs(t, err, replicate.ErrWebhookTimestampOutOfTolerance)
+
+	req = newOldRequest()
+	req.Header.Set("Webhook-Timestamp", "yesterday")
+	_, err = replicate.ValidateWebhookRequestWithTolerance(req, testSecret, 5*time.Minute)
+	assert.ErrorIs(t, err, replicate.ErrWebhookMissingHeaders)
+}
+
+func TestValidateWebhookErrors(t *testing.T) {
+	// This is a test secret and should not be used in production
+	testSecret := replicate.WebhookSigningSecret{
+		Key: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", // nolint:gosec
+	}
+	body := `{"id": "ufawqhfynnddngldkgtslldrkq", "status": "succeeded"}`
+
+	testCases := []struct {
+		name    string
+		secret  replicate.WebhookSigningSecret
+		modify  func(req *http.Request)
+		wantErr error
+	}{
+		{
+			name:    "missing signature",
+			secret:  testSecret,
+			modify:  func(req *http.Request) { req.Header.Del("Webhook-Signature") },
+			wantErr: replicate.ErrWebhookMissingHeaders,
+		},
+		{
+			name:    "malformed signature",
+			secret:  testSecret,
+			modify:  func(req *http.Request) { req.Header.Set("Webhook-Signature", "v1") },
+			wantErr: replicate.ErrWebhookMissingHeaders,
+		},
+		{
+			name:    "secret without prefix",
+			secret:  replicate.WebhookSigningSecret{Key: "MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"},
+			wantErr: replicate.ErrWebhookInvalidSecret,
+		},
+		{
+			name:    "secret not base64",
+			secret:  replicate.WebhookSigningSecret{Key: "whsec_not base64!"},
+			wantErr: replicate.ErrWebhookInvalidSecret,
+		},
+		{
+			name:   "signature mismatch",
+			secret: testSecret,
+			modify: func(req *http.Request) {
+				req.Header.Set("Webhook-Signature", "v1,"+base64.StdEncoding.EncodeToString([]byte("forged")))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newSignedWebhookRequest(t, testSecret, body)
+			if tc.modify != nil {
+				tc.modify(req)
+			}
+
+			isValid, err := replicate.ValidateWebhookRequest(req, tc.secret)
+			assert.False(t, isValid)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	// This is a test secret and should not be used in production
+	testSecret := replicate.WebhookSigningSecret{
+		Key: "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", // nolint:gosec
+	}
+
+	testCases := []struct {
+		name           string
+		body           string
+		wantPrediction bool
+		wantTraining   bool
+	}{
+		{
+			name:           "Prediction",
+			body:           `{"id": "ufawqhfynnddngldkgtslldrkq", "status": "succeeded", "output": "Hello, Alice", "urls": {"get": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq"}}`,
+			wantPrediction: true,
+		},
+		{
+			name:         "Training",
+			body:         `{"id": "zz4ibbonubfz7carwiefibzgga", "status": "succeeded", "destination": "owner/model", "urls": {"get": "https://api.replicate.com/v1/trainings/zz4ibbonubfz7carwiefibzgga"}}`,
+			wantTraining: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := newSignedWebhookRequest(t, testSecret, tc.body)
+
+			event, err := replicate.ParseWebhookEvent(req, testSecret)
+			require.NoError(t, err)
+
+			if tc.wantPrediction {
+				require.NotNil(t, event.Prediction)
+				assert.Nil(t, event.Training)
+				assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", event.Prediction.ID)
+				assert.Equal(t, "Hello, Alice", event.Prediction.Output)
+			}
+			if tc.wantTraining {
+				require.NotNil(t, event.Training)
+				assert.Nil(t, event.Prediction)
+				assert.Equal(t, "zz4ibbonubfz7carwiefibzgga", event.Training.ID)
+				assert.Equal(t, replicate.Succeeded, event.Training.Status)
+			}
+
+			// Ensure that the request body is available after parsing
+			bodyBytes, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.body, string(bodyBytes))
+		})
+	}
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		req := newSignedWebhookRequest(t, testSecret, `{"id": "ufawqhfynnddngldkgtslldrkq"}`)
+		req.Header.Set("Webhook-ID", "msg_tampered")
+
+		_, err := replicate.ParseWebhookEvent(req, testSecret)
+		assert.ErrorIs(t, err, replicate.ErrInvalidWebhookSignature)
+	})
+}
+
+// newSignedWebhookRequest creates a webhook request signed with the given secret.
+func newSignedWebhookRequest(t *testing.T, secret replicate.WebhookSigningSecret, body string) *http.Request {
+	t.Helper()
+
+	id := "msg_p5jXN8AQM9LWM0D4loKWxJek"
+	timestamp := fmt.Sprintf("%d", time.Now().Unix())
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret.Key, "whsec_"))
+	require.NoError(t, err)
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(fmt.Sprintf("%s.%s.%s", id, timestamp, body)))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "http://test.host/webhook", strings.NewReader(body))
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Webhook-ID", id)
+	req.Header.Add("Webhook-Timestamp", timestamp)
+	req.Header.Add("Webhook-Signature", "v1,"+signature)
+	return req
+}
+
 func TestGetDeployment(t *testing.T) {
 	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/deployments/acme/image-upscaler", r.URL.Path)
@@ -2191,6 +6231,69 @@ func TestListDeployments(t *testing.T) {
 	assert.Equal(t, 10, deployments.Results[1].CurrentRelease.Configuration.MaxInstances)
 }
 
+func TestListDeploymentPredictions(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/deployments/acme/image-upscaler/predictions", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		var response replicate.Page[replicate.Prediction]
+
+		mockCursor := "cD0yMDIyLTAxLTIxKzIzJTNBMTglM0EyNC41MzAzNTclMkIwMCUzQTAw"
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			next := "/deployments/acme/image-upscaler/predictions?cursor=" + mockCursor
+			response = replicate.Page[replicate.Prediction]{
+				Next: &next,
+				Results: []replicate.Prediction{
+					{ID: "ufawqhfynnddngldkgtslldrkq", Status: replicate.Succeeded},
+				},
+			}
+		case mockCursor:
+			response = replicate.Page[replicate.Prediction]{
+				Results: []replicate.Prediction{
+					{ID: "rrr4z55ocneqzikepnug6xezpe", Status: replicate.Processing},
+				},
+			}
+		}
+
+		responseBytes, err := json.Marshal(response)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(responseBytes)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	initialPage, err := client.ListDeploymentPredictions(ctx, "acme", "image-upscaler")
+	require.NoError(t, err)
+
+	resultsChan, errChan := replicate.Paginate(ctx, client, initialPage)
+
+	var predictions []replicate.Prediction
+	for results := range resultsChan {
+		predictions = append(predictions, results...)
+	}
+	require.NoError(t, <-errChan)
+
+	assert.Len(t, predictions, 2)
+	assert.Equal(t, "ufawqhfynnddngldkgtslldrkq", predictions[0].ID)
+	assert.Equal(t, replicate.Succeeded, predictions[0].Status)
+	assert.Equal(t, "rrr4z55ocneqzikepnug6xezpe", predictions[1].ID)
+}
+
 func TestCreateDeployment(t *testing.T) {
 	owner := replicate.Account{
 		Type:     "organization",
@@ -2213,8 +6316,8 @@ func TestCreateDeployment(t *testing.T) {
 				Model:        "acme/new-model",
 				Version:      "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
 				Hardware:     "gpu-t4",
-				MinInstances: 1,
-				MaxInstances: 5,
+				MinInstances: ptrToInt(1),
+				MaxInstances: ptrToInt(5),
 			},
 			want: &replicate.Deployment{
 				Owner: owner.Username,
@@ -2234,6 +6337,32 @@ func TestCreateDeployment(t *testing.T) {
 			},
 			wantError: false,
 		},
+		{
+			name: "Default Scaling",
+			options: replicate.CreateDeploymentOptions{
+				Name:     "default-deployment",
+				Model:    "acme/new-model",
+				Version:  "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+				Hardware: "gpu-t4",
+			},
+			want: &replicate.Deployment{
+				Owner: owner.Username,
+				Name:  "default-deployment",
+				CurrentRelease: replicate.DeploymentRelease{
+					Number:  1,
+					Model:   "acme/new-model",
+					Version: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+					Configuration: replicate.DeploymentConfiguration{
+						Hardware:     "gpu-t4",
+						MinInstances: 0,
+						MaxInstances: 1,
+					},
+					CreatedBy: owner,
+					CreatedAt: timestamp,
+				},
+			},
+			wantError: false,
+		},
 		{
 			name: "Failed Creation",
 			options: replicate.CreateDeploymentOptions{
@@ -2248,8 +6377,11 @@ func TestCreateDeployment(t *testing.T) {
 		require.Equal(t, http.MethodPost, r.Method)
 		require.Equal(t, "/deployments", r.URL.Path)
 
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
 		var options replicate.CreateDeploymentOptions
-		if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		if err := json.Unmarshal(body, &options); err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			return
 		}
@@ -2259,6 +6391,21 @@ func TestCreateDeployment(t *testing.T) {
 			return
 		}
 
+		// Omitted instance counts fall back to the default scaling
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(body, &fields))
+		minInstances, maxInstances := 0, 1
+		if options.MinInstances != nil {
+			minInstances = *options.MinInstances
+		} else {
+			assert.NotContains(t, fields, "min_instances")
+		}
+		if options.MaxInstances != nil {
+			maxInstances = *options.MaxInstances
+		} else {
+			assert.NotContains(t, fields, "max_instances")
+		}
+
 		response := replicate.Deployment{
 			Owner: owner.Username,
 			Name:  options.Name,
@@ -2268,8 +6415,8 @@ func TestCreateDeployment(t *testing.T) {
 				Version: options.Version,
 				Configuration: replicate.DeploymentConfiguration{
 					Hardware:     options.Hardware,
-					MinInstances: options.MinInstances,
-					MaxInstances: options.MaxInstances,
+					MinInstances: minInstances,
+					MaxInstances: maxInstances,
 				},
 				CreatedBy: owner,
 				CreatedAt: timestamp,
@@ -2569,6 +6716,88 @@ func TestDeleteModel(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDeleteModelWithVersions(t *testing.T) {
+	testCases := []struct {
+		name         string
+		failVersion  string
+		wantDeleted  []string
+		wantErr      bool
+		modelDeleted bool
+	}{
+		{
+			name:         "All versions deleted",
+			wantDeleted:  []string{"v3", "v2", "v1"},
+			modelDeleted: true,
+		},
+		{
+			name:        "Version deletion fails",
+			failVersion: "v2",
+			wantDeleted: []string{"v3", "v1"},
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var deleted []string
+			modelDeleted := false
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/models/acme/hello-world/versions":
+					var page replicate.Page[replicate.ModelVersion]
+					if r.URL.Query().Get("cursor") == "" {
+						next := "/models/acme/hello-world/versions?cursor=next"
+						page = replicate.Page[replicate.ModelVersion]{
+							Next:    &next,
+							Results: []replicate.ModelVersion{{ID: "v3"}, {ID: "v2"}},
+						}
+					} else {
+						page = replicate.Page[replicate.ModelVersion]{
+							Results: []replicate.ModelVersion{{ID: "v1"}},
+						}
+					}
+					json.NewEncoder(w).Encode(page)
+				case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/models/acme/hello-world/versions/"):
+					id := strings.TrimPrefix(r.URL.Path, "/models/acme/hello-world/versions/")
+					if id == tc.failVersion {
+						w.WriteHeader(http.StatusConflict)
+						json.NewEncoder(w).Encode(replicate.APIError{Detail: "version has predictions"})
+						return
+					}
+					deleted = append(deleted, id)
+					w.WriteHeader(http.StatusAccepted)
+				case r.Method == http.MethodDelete && r.URL.Path == "/models/acme/hello-world":
+					modelDeleted = true
+					w.WriteHeader(http.StatusAccepted)
+				default:
+					t.Fatalf("unexpected request: %s %s", r.Method, r.URL.Path)
+				}
+			}))
+			defer mockServer.Close()
+
+			client, err := replicate.NewClient(
+				replicate.WithToken("test-token"),
+				replicate.WithBaseURL(mockServer.URL),
+			)
+			require.NotNil(t, client)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err = client.DeleteModelWithVersions(ctx, "acme", "hello-world")
+			if tc.wantErr {
+				assert.ErrorContains(t, err, "version has predictions")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.wantDeleted, deleted)
+			assert.Equal(t, tc.modelDeleted, modelDeleted)
+		})
+	}
+}
+
 // Helper functions to create pointers for the UpdateDeploymentOptions fields
 func ptrToString(s string) *string {
 	return &s
@@ -29,6 +29,16 @@ func (c *Collection) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Collection{}
+
+func (c Collection) MarshalJSON() ([]byte, error) {
+	if len(c.rawJSON) > 0 {
+		return c.rawJSON, nil
+	}
+	type Alias Collection
+	return json.Marshal(Alias(c))
+}
+
 // ListCollections returns a list of all collections.
 func (r *Client) ListCollections(ctx context.Context) (*Page[Collection], error) {
 	response := &Page[Collection]{}
@@ -48,3 +58,16 @@ func (r *Client) GetCollection(ctx context.Context, slug string) (*Collection, e
 	}
 	return collection, nil
 }
+
+// ListCollectionModels returns the models in a collection.
+//
+// GetCollection only includes the first page of a large collection's models,
+// so use this with Paginate to get all of them.
+func (r *Client) ListCollectionModels(ctx context.Context, slug string) (*Page[Model], error) {
+	response := &Page[Model]{}
+	err := r.fetch(ctx, http.MethodGet, fmt.Sprintf("/collections/%s/models", slug), nil, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collection models: %w", err)
+	}
+	return response, nil
+}
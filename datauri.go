@@ -0,0 +1,52 @@
+package replicate
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strings"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+const defaultDataURIContentType = "application/octet-stream"
+
+// DataURIFromBytes returns a base64-encoded data URI with the given content type and data,
+// such as "data:image/png;base64,iVBORw0KGgo...".
+//
+// Data URIs can be passed as prediction inputs in place of files,
+// which avoids uploading small files before creating a prediction:
+//
+//	input := replicate.PredictionInput{"image": replicate.DataURIFromBytes("image/png", b)}
+//
+// If contentType is empty or invalid, "application/octet-stream" is used.
+func DataURIFromBytes(contentType string, data []byte) string {
+	mediatype, params, err := mime.ParseMediaType(contentType)
+	if err != nil || strings.Count(mediatype, "/") != 1 {
+		mediatype, params = defaultDataURIContentType, nil
+	}
+
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key, params[key])
+	}
+
+	return dataurl.New(data, mediatype, pairs...).String()
+}
+
+// DataURIFromReader reads r to the end and returns a data URI with its contents,
+// like DataURIFromBytes.
+func DataURIFromReader(contentType string, r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read data: %w", err)
+	}
+	return DataURIFromBytes(contentType, data), nil
+}
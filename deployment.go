@@ -43,21 +43,31 @@ func (d *Deployment) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Deployment{}
+
+func (d Deployment) MarshalJSON() ([]byte, error) {
+	if len(d.rawJSON) > 0 {
+		return d.rawJSON, nil
+	}
+	type Alias Deployment
+	return json.Marshal(Alias(d))
+}
+
 // CreateDeploymentPrediction sends a request to the Replicate API to create a prediction using the specified deployment.
 func (c *Client) CreatePredictionWithDeployment(ctx context.Context, deploymentOwner string, deploymentName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error) {
-	path := fmt.Sprintf("/deployments/%s/%s/predictions", deploymentOwner, deploymentName)
+	return c.CreatePredictionWithOptions(ctx, positionalPredictionOptions(WithPredictionDeployment(deploymentOwner, deploymentName), input, webhook, stream)...)
+}
 
-	req, err := c.createPredictionRequest(ctx, path, nil, input, webhook, stream)
+// ListDeploymentPredictions lists predictions created with the specified deployment.
+func (c *Client) ListDeploymentPredictions(ctx context.Context, deploymentOwner string, deploymentName string) (*Page[Prediction], error) {
+	response := &Page[Prediction]{}
+	path := fmt.Sprintf("/deployments/%s/%s/predictions", deploymentOwner, deploymentName)
+	err := c.fetch(ctx, http.MethodGet, path, nil, response)
 	if err != nil {
-		return nil, err
-	}
-
-	prediction := &Prediction{}
-	if err := c.do(req, prediction); err != nil {
-		return nil, fmt.Errorf("failed to create prediction with deployment: %w", err)
+		return nil, fmt.Errorf("failed to list deployment predictions: %w", err)
 	}
 
-	return prediction, nil
+	return response, nil
 }
 
 // GetDeployment retrieves the details of a specific deployment.
@@ -83,13 +93,16 @@ func (c *Client) ListDeployments(ctx context.Context) (*Page[Deployment], error)
 	return response, nil
 }
 
+// CreateDeploymentOptions are the options for creating a deployment.
+// MinInstances and MaxInstances are optional, like in UpdateDeploymentOptions:
+// if they're nil, the deployment uses the default scaling.
 type CreateDeploymentOptions struct {
-	Name         string `json:"name"`
-	Model        string `json:"model"`
-	Version      string `json:"version"`
-	Hardware     string `json:"hardware"`
-	MinInstances int    `json:"min_instances"`
-	MaxInstances int    `json:"max_instances"`
+	Name         string      `json:"name"`
+	Model        string      `json:"model"`
+	Version      string      `json:"version"`
+	Hardware     HardwareSKU `json:"hardware"`
+	MinInstances *int        `json:"min_instances,omitempty"`
+	MaxInstances *int        `json:"max_instances,omitempty"`
 }
 
 // CreateDeployment creates a new deployment.
@@ -105,11 +118,11 @@ func (c *Client) CreateDeployment(ctx context.Context, options CreateDeploymentO
 }
 
 type UpdateDeploymentOptions struct {
-	Model        *string `json:"model,omitempty"`
-	Version      *string `json:"version,omitempty"`
-	Hardware     *string `json:"hardware,omitempty"`
-	MinInstances *int    `json:"min_instances,omitempty"`
-	MaxInstances *int    `json:"max_instances,omitempty"`
+	Model        *string      `json:"model,omitempty"`
+	Version      *string      `json:"version,omitempty"`
+	Hardware     *HardwareSKU `json:"hardware,omitempty"`
+	MinInstances *int         `json:"min_instances,omitempty"`
+	MaxInstances *int         `json:"max_instances,omitempty"`
 }
 
 // UpdateDeployment updates an existing deployment.
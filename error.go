@@ -1,10 +1,14 @@
 package replicate
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // APIError represents an error returned by the Replicate API
@@ -23,22 +27,93 @@ type APIError struct {
 
 	// Instance is a URI that identifies the specific occurrence of the error.
 	Instance string `json:"instance,omitempty"`
+
+	// RetryAfter is how long to wait before retrying the request,
+	// as indicated by the Retry-After response header.
+	// It's zero if the response didn't include the header.
+	RetryAfter time.Duration `json:"-"`
+
+	// RawBody is the body of the response, if it couldn't be parsed as JSON.
+	// This is the case for errors returned by proxies and gateways,
+	// which are often HTML pages.
+	RawBody string `json:"-"`
 }
 
+// maxRawBodySnippet is the maximum number of characters of RawBody included in an error message.
+const maxRawBodySnippet = 200
+
 func unmarshalAPIError(resp *http.Response, data []byte) *APIError {
 	apiError := APIError{}
-	err := json.Unmarshal(data, &apiError)
-	if err != nil {
-		apiError.Detail = fmt.Sprintf("Unknown error: %s", err)
+	if isJSONResponse(resp, data) {
+		err := json.Unmarshal(data, &apiError)
+		if err != nil {
+			apiError.Detail = fmt.Sprintf("Unknown error: %s", err)
+			apiError.RawBody = string(data)
+		}
+	} else {
+		apiError.RawBody = string(data)
+	}
+
+	if resp != nil {
+		if apiError.Status == 0 {
+			apiError.Status = resp.StatusCode
+		}
+
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			apiError.RetryAfter = max(retryAfter, 0)
+		}
 	}
 
-	if apiError.Status == 0 && resp != nil {
-		apiError.Status = resp.StatusCode
+	if apiError.Detail == "" && apiError.RawBody != "" {
+		apiError.Detail = fmt.Sprintf("non-JSON error response (status %d)", apiError.Status)
 	}
 
 	return &apiError
 }
 
+// isJSONResponse returns false if the response body is evidently not JSON,
+// because it's served as HTML or looks like markup.
+func isJSONResponse(resp *http.Response, data []byte) bool {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return false
+	}
+
+	if resp != nil {
+		mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if err == nil && (mediaType == "text/html" || mediaType == "application/xhtml+xml") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseRetryAfter parses the value of a Retry-After header,
+// which is either an HTTP date or a number of seconds.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if date, err := time.Parse(time.RFC1123, value); err == nil {
+		return time.Until(date), true
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	return 0, false
+}
+
+// IsRetryable returns true if the request that caused the error may succeed if retried,
+// which is the case for rate limiting (429) and server (5xx) errors.
+//
+// Retrying a request that isn't idempotent after a server error may repeat its effects.
+func (e *APIError) IsRetryable() bool {
+	return e.Status == http.StatusTooManyRequests || (e.Status >= 500 && e.Status < 600)
+}
+
 func (e APIError) Error() string {
 	components := []string{}
 	if e.Type != "" {
@@ -53,6 +128,14 @@ func (e APIError) Error() string {
 		components = append(components, e.Detail)
 	}
 
+	if e.RawBody != "" {
+		snippet := strings.Join(strings.Fields(e.RawBody), " ")
+		if runes := []rune(snippet); len(runes) > maxRawBodySnippet {
+			snippet = string(runes[:maxRawBodySnippet]) + "..."
+		}
+		components = append(components, snippet)
+	}
+
 	output := strings.Join(components, ": ")
 	if output == "" {
 		output = "unknown error"
@@ -71,6 +154,10 @@ func (e *APIError) WriteHTTPResponse(w http.ResponseWriter) {
 		status = e.Status
 	}
 
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(e.RetryAfter.Round(time.Second)/time.Second)))
+	}
+
 	w.WriteHeader(status)
 	err := json.NewEncoder(w).Encode(e)
 	if err != nil {
@@ -79,6 +166,79 @@ func (e *APIError) WriteHTTPResponse(w http.ResponseWriter) {
 	}
 }
 
+// requestIDHeaders are the response headers that identify a request, in order of preference.
+var requestIDHeaders = []string{"Replicate-Request-ID", "X-Request-ID"}
+
+// RequestError wraps an error for a request with the ID of the request,
+// which can be used to reference the request in support tickets.
+type RequestError struct {
+	// RequestID is the ID of the request, from the response headers.
+	RequestID string
+
+	// Err is the underlying error, such as an *APIError.
+	Err error
+}
+
+func requestIDFromHeader(header http.Header) string {
+	for _, key := range requestIDHeaders {
+		if id := header.Get(key); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+func (e *RequestError) Error() string {
+	return fmt.Sprintf("%s (request ID: %s)", e.Err, e.RequestID)
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// PredictionError represents the error reported by a failed prediction.
+type PredictionError struct {
+	// Message is a human-readable explanation of the error.
+	Message string `json:"message,omitempty"`
+
+	// Details is the structured error object, if the API returned one.
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// newPredictionError converts the raw error value of a prediction into a PredictionError.
+func newPredictionError(v interface{}) *PredictionError {
+	switch e := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return &PredictionError{Message: e}
+	case map[string]interface{}:
+		predictionError := &PredictionError{Details: e}
+		for _, key := range []string{"message", "detail", "error"} {
+			if message, ok := e[key].(string); ok {
+				predictionError.Message = message
+				break
+			}
+		}
+		if predictionError.Message == "" {
+			if b, err := json.Marshal(e); err == nil {
+				predictionError.Message = string(b)
+			}
+		}
+		return predictionError
+	default:
+		return &PredictionError{Message: fmt.Sprintf("%v", e)}
+	}
+}
+
+func (e *PredictionError) Error() string {
+	if e.Message == "" {
+		return "unknown prediction error"
+	}
+
+	return e.Message
+}
+
 // ModelError represents an error returned by a model for a failed prediction.
 type ModelError struct {
 	Prediction *Prediction `json:"prediction"`
@@ -89,5 +249,5 @@ func (e *ModelError) Error() string {
 		return "unknown model error"
 	}
 
-	return fmt.Sprintf("model error: %s", e.Prediction.Error)
+	return fmt.Sprintf("model error: %s", e.Prediction.ErrorString())
 }
@@ -28,7 +28,12 @@ func ExampleClient_Run() {
 		panic(err)
 	}
 
-	fmt.Printf("Generated %d image(s)\n", len(output.([]any)))
+	urls, err := replicate.OutputAsStrings(output)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Generated %d image(s)\n", len(urls))
 	// Output: Generated 1 image(s)
 }
 
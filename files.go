@@ -3,15 +3,25 @@ package replicate
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"mime"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrChecksumMismatch = errors.New("file checksum mismatch")
 )
 
 type File struct {
@@ -38,10 +48,28 @@ func (f *File) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = File{}
+
+func (f File) MarshalJSON() ([]byte, error) {
+	if len(f.rawJSON) > 0 {
+		return f.rawJSON, nil
+	}
+	type Alias File
+	return json.Marshal(Alias(f))
+}
+
 func (f *File) RawJSON() json.RawMessage {
 	return f.rawJSON
 }
 
+// FileRef is a prediction input that is uploaded as a file
+// when the prediction is created.
+type FileRef struct {
+	Reader      io.Reader
+	Filename    string
+	ContentType string
+}
+
 type CreateFileOptions struct {
 	Filename    string            `json:"filename"`
 	ContentType string            `json:"content_type"`
@@ -98,11 +126,73 @@ func (r *Client) CreateFileFromBuffer(ctx context.Context, buf *bytes.Buffer, op
 	return r.createFile(ctx, buf, *options)
 }
 
+// CreateFileFromReader creates a new file from a reader.
+//
+// Unlike the other CreateFile methods, the upload is streamed
+// rather than buffered in memory, so it's suitable for large files.
+// Because the reader can only be read once, the request isn't retried.
+func (r *Client) CreateFileFromReader(ctx context.Context, reader io.Reader, options *CreateFileOptions) (*File, error) {
+	if options == nil {
+		options = &CreateFileOptions{}
+	}
+
+	if options.ContentType == "" && options.Filename != "" {
+		options.ContentType = mime.TypeByExtension(filepath.Ext(options.Filename))
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	writer := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeFileForm(writer, reader, *options))
+	}()
+
+	req, err := r.newRequest(ctx, http.MethodPost, "/files", pr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// The length isn't known in advance, so the body is sent with chunked transfer encoding
+	req.ContentLength = -1
+
+	file := &File{}
+	err = r.do(req, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	return file, nil
+}
+
 // CreateFile creates a new file.
 func (r *Client) createFile(ctx context.Context, reader io.Reader, options CreateFileOptions) (*File, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
+	err := writeFileForm(writer, reader, options)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := r.newRequest(ctx, http.MethodPost, "/files", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	file := &File{}
+	err = r.do(req, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	return file, nil
+}
+
+// writeFileForm writes the file content and metadata as multipart form fields,
+// and closes the writer.
+func writeFileForm(writer *multipart.Writer, reader io.Reader, options CreateFileOptions) error {
 	filename := options.Filename
 	if filename == "" {
 		filename = "file"
@@ -119,48 +209,138 @@ func (r *Client) createFile(ctx context.Context, reader io.Reader, options Creat
 
 	content, err := writer.CreatePart(h)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
+		return fmt.Errorf("failed to create form file: %w", err)
 	}
 	_, err = io.Copy(content, reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write file to form: %w", err)
+		return fmt.Errorf("failed to write file to form: %w", err)
 	}
 
 	if options.Metadata != nil {
 		metadata, err := json.Marshal(options.Metadata)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+			return fmt.Errorf("failed to marshal metadata: %w", err)
 		}
 		err = writer.WriteField("metadata", string(metadata))
 		if err != nil {
-			return nil, fmt.Errorf("failed to write metadata to form: %w", err)
+			return fmt.Errorf("failed to write metadata to form: %w", err)
 		}
 	}
 
 	err = writer.Close()
 	if err != nil {
-		return nil, fmt.Errorf("failed to close writer: %w", err)
+		return fmt.Errorf("failed to close writer: %w", err)
 	}
 
-	req, err := r.newRequest(ctx, http.MethodPost, "/files", body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	return nil
+}
+
+// inputFileRef returns the file to upload for a FileRef or io.Reader prediction input value.
+// It returns false if the value isn't a file to upload.
+func inputFileRef(value interface{}) (FileRef, bool) {
+	switch v := value.(type) {
+	case FileRef:
+		return v, true
+	case *FileRef:
+		return *v, true
+	case *os.File:
+		return FileRef{Reader: v, Filename: filepath.Base(v.Name())}, true
+	case io.Reader:
+		return FileRef{Reader: v}, true
+	default:
+		return FileRef{}, false
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+}
 
-	file := &File{}
-	err = r.do(req, file)
+// uploadInputFile uploads a FileRef or io.Reader prediction input value and returns its "get" URL.
+// It returns false if the value isn't a file to upload.
+func (r *Client) uploadInputFile(ctx context.Context, value interface{}) (string, bool, error) {
+	ref, ok := inputFileRef(value)
+	if !ok {
+		return "", false, nil
+	}
+
+	options := CreateFileOptions{
+		Filename:    ref.Filename,
+		ContentType: ref.ContentType,
+	}
+	if options.ContentType == "" && options.Filename != "" {
+		options.ContentType = mime.TypeByExtension(filepath.Ext(options.Filename))
+	}
+
+	file, err := r.createFile(ctx, ref.Reader, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
+		return "", true, err
 	}
 
-	return file, nil
+	return file.URLs["get"], true, nil
+}
+
+// ListFilesOption is a function that modifies listFilesOptions.
+type ListFilesOption func(*listFilesOptions)
+
+// listFilesOptions represents filters and ordering for listing files
+type listFilesOptions struct {
+	ordering string
+	metadata map[string]string
+}
+
+// WithFilesCreatedAtOrder orders the listed files by when they were created,
+// oldest first if ascending is true, or newest first otherwise.
+func WithFilesCreatedAtOrder(ascending bool) ListFilesOption {
+	return func(o *listFilesOptions) {
+		if ascending {
+			o.ordering = "created_at"
+		} else {
+			o.ordering = "-created_at"
+		}
+	}
+}
+
+// WithFilesMetadata only lists files whose metadata has the given key and value.
+// It can be given more than once to filter by several keys.
+func WithFilesMetadata(key string, value string) ListFilesOption {
+	return func(o *listFilesOptions) {
+		if o.metadata == nil {
+			o.metadata = map[string]string{}
+		}
+		o.metadata[key] = value
+	}
+}
+
+func (o *listFilesOptions) query() url.Values {
+	query := url.Values{}
+	if o.ordering != "" {
+		query.Set("ordering", o.ordering)
+	}
+	for key, value := range o.metadata {
+		query.Set("metadata."+key, value)
+	}
+	return query
 }
 
 // ListFiles lists your files.
 func (r *Client) ListFiles(ctx context.Context) (*Page[File], error) {
+	return r.ListFilesWithOptions(ctx)
+}
+
+// ListFilesWithOptions returns a paginated list of your files, filtered and ordered by the given options.
+//
+// The options are encoded in the next page URL returned by the API,
+// so subsequent pages fetched with Paginate remain filtered.
+func (r *Client) ListFilesWithOptions(ctx context.Context, opts ...ListFilesOption) (*Page[File], error) {
+	options := &listFilesOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	path := "/files"
+	if query := options.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
 	response := &Page[File]{}
-	err := r.fetch(ctx, http.MethodGet, "/files", nil, response)
+	err := r.fetch(ctx, http.MethodGet, path, nil, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files: %w", err)
 	}
@@ -179,12 +359,122 @@ func (r *Client) GetFile(ctx context.Context, fileID string) (*File, error) {
 	return file, nil
 }
 
+// DownloadFile retrieves the contents of a file.
+//
+// The file's "get" URL is requested with the client's auth token
+// if it's served by the API, or without it if it's a pre-signed URL on another host.
+// It is the caller's responsibility to close the returned io.ReadCloser.
+func (r *Client) DownloadFile(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	file, err := r.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.downloadFile(ctx, file)
+}
+
+// DownloadFileVerified retrieves the contents of a file like DownloadFile,
+// computing the SHA-256 checksum of the contents as they're read.
+// If the checksum doesn't match the file's "sha256" checksum,
+// reading the last of the contents returns an error wrapping ErrChecksumMismatch
+// instead of io.EOF, so truncated or corrupted downloads aren't mistaken for complete ones.
+func (r *Client) DownloadFileVerified(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	file, err := r.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := file.Checksums["sha256"]
+	if expected == "" {
+		return nil, fmt.Errorf("failed to download file: file %s has no sha256 checksum", fileID)
+	}
+
+	body, err := r.downloadFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &verifyingReader{ReadCloser: body, hash: sha256.New(), expected: expected}, nil
+}
+
+// verifyingReader hashes the contents read from it,
+// and compares the checksum to the expected one at EOF.
+type verifyingReader struct {
+	io.ReadCloser
+	hash     hash.Hash
+	expected string
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	v.hash.Write(p[:n])
+	if err == io.EOF {
+		actual := hex.EncodeToString(v.hash.Sum(nil))
+		if !strings.EqualFold(actual, v.expected) {
+			return n, fmt.Errorf("%w: expected sha256 %s, got %s", ErrChecksumMismatch, v.expected, actual)
+		}
+	}
+	return n, err
+}
+
+func (r *Client) downloadFile(ctx context.Context, file *File) (io.ReadCloser, error) {
+	contentURL := file.URLs["get"]
+	if contentURL == "" {
+		return nil, fmt.Errorf("failed to download file: file %s has no download URL", file.ID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, contentURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if r.isAPIURL(req.URL) {
+		req.Header.Set("Authorization", r.authorization())
+	}
+	if r.options.userAgent != nil {
+		req.Header.Set("User-Agent", *r.options.userAgent)
+	}
+
+	resp, err := r.c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		body, err := r.readResponseBody(resp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, fmt.Errorf("failed to download file: %w", unmarshalAPIError(resp, body))
+	}
+
+	return resp.Body, nil
+}
+
+// isAPIURL returns true if u is served by the same host as the client's base URL,
+// so requests to it should be authenticated.
+func (r *Client) isAPIURL(u *url.URL) bool {
+	baseURL, err := url.Parse(r.options.baseURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == baseURL.Scheme && u.Host == baseURL.Host
+}
+
 // DeleteFile deletes a file.
 func (r *Client) DeleteFile(ctx context.Context, fileID string) error {
-	err := r.fetch(ctx, http.MethodDelete, fmt.Sprintf("/files/%s", fileID), nil, nil)
+	_, err := r.DeleteFileWithResult(ctx, fileID)
+	return err
+}
+
+// DeleteFileWithResult deletes a file, and returns the API's response.
+func (r *Client) DeleteFileWithResult(ctx context.Context, fileID string) (*DeleteResult, error) {
+	result := &DeleteResult{}
+	err := r.fetch(ctx, http.MethodDelete, fmt.Sprintf("/files/%s", fileID), nil, deleteResultWriter{result})
 	if err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+		return nil, fmt.Errorf("failed to delete file: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
@@ -8,6 +8,7 @@ require (
 	github.com/stretchr/testify v1.8.4
 	github.com/vincent-petithory/dataurl v1.0.0
 	golang.org/x/sync v0.6.0
+	golang.org/x/time v0.5.0
 )
 
 require (
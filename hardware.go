@@ -3,10 +3,36 @@ package replicate
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+var (
+	ErrUnknownHardware = errors.New("unknown hardware")
+)
+
+// HardwareSKU identifies the hardware a model or deployment runs on,
+// for the Hardware fields of CreateModelOptions, CreateDeploymentOptions,
+// and UpdateDeploymentOptions.
+//
+// It's an alias for string, so raw strings and the constants below
+// can be used interchangeably.
+// Replicate adds new hardware over time,
+// so any SKU returned by ListHardware can be used.
+type HardwareSKU = string
+
+// Known hardware SKUs.
+const (
+	HardwareCPU          HardwareSKU = "cpu"
+	HardwareGPUT4        HardwareSKU = "gpu-t4"
+	HardwareGPUL40S      HardwareSKU = "gpu-l40s"
+	HardwareGPUA40Small  HardwareSKU = "gpu-a40-small"
+	HardwareGPUA40Large  HardwareSKU = "gpu-a40-large"
+	HardwareGPUA100Large HardwareSKU = "gpu-a100-large"
+	HardwareGPUH100      HardwareSKU = "gpu-h100"
+)
+
 type Hardware struct {
 	SKU  string `json:"sku"`
 	Name string `json:"name"`
@@ -27,6 +53,16 @@ func (h *Hardware) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Hardware{}
+
+func (h Hardware) MarshalJSON() ([]byte, error) {
+	if len(h.rawJSON) > 0 {
+		return h.rawJSON, nil
+	}
+	type Alias Hardware
+	return json.Marshal(Alias(h))
+}
+
 // ListHardware returns a list of available hardware.
 func (r *Client) ListHardware(ctx context.Context) (*[]Hardware, error) {
 	response := &[]Hardware{}
@@ -36,3 +72,21 @@ func (r *Client) ListHardware(ctx context.Context) (*[]Hardware, error) {
 	}
 	return response, nil
 }
+
+// ValidateHardware checks that the SKU is available,
+// according to the hardware returned by ListHardware.
+// It returns an error wrapping ErrUnknownHardware if it isn't.
+func (r *Client) ValidateHardware(ctx context.Context, sku HardwareSKU) error {
+	hardware, err := r.ListHardware(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, h := range *hardware {
+		if h.SKU == sku {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownHardware, sku)
+}
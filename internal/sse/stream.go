@@ -46,17 +46,27 @@ func (s *Streamer) connect(ctx context.Context) error {
 			return ErrMaximumRetries
 		}
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		delay := 0 * time.Second
 		if s.attempt > 0 {
 			// delay on connection retry
 			delay = s.backoff.NextDelay(s.attempt - 1)
 		}
 		s.attempt++
+
+		// don't wait for a reconnect that can't happen before the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return fmt.Errorf("reconnect delay of %s exceeds context deadline: %w", delay, context.DeadlineExceeded)
+		}
+
 		reconnectDelay := time.NewTimer(delay)
 		// once we only support go 1.23+, we can use time.After() here and simplify
-		defer reconnectDelay.Stop()
 		select {
 		case <-ctx.Done():
+			reconnectDelay.Stop()
 			return ctx.Err()
 		case <-reconnectDelay.C:
 		}
@@ -103,6 +113,14 @@ func (s *Streamer) NextEvent(ctx context.Context) (*Event, error) {
 	for {
 		e, err := s.decoder.Next()
 		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				// The context was canceled while reading the event. Return the
+				// lines received so far, and the context error on the next call.
+				if e.Data != "" {
+					return &e, nil
+				}
+				return nil, ctxErr
+			}
 			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 				if err = s.connect(ctx); err != nil {
 					return nil, err
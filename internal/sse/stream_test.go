@@ -146,3 +146,30 @@ id: 3
 	assert.Equal(t, "", e.Data)
 	assert.Equal(t, "3", e.ID)
 }
+
+func TestStreamReconnectRespectsDeadline(t *testing.T) {
+	// The server sends an event, then drops the connection, forever
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `event: output
+data: foo
+
+`)
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	t.Cleanup(cancel)
+
+	s := sse.NewStreamer(http.DefaultClient, ts.URL, 10, &replicate.ConstantBackoff{Base: 10 * time.Second})
+	t.Cleanup(func() { s.Close() })
+
+	e, err := s.NextEvent(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "foo\n", e.Data)
+
+	// The reconnect delay is longer than the time left, so it fails without waiting
+	start := time.Now()
+	_, err = s.NextEvent(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+}
@@ -0,0 +1,87 @@
+//go:build go1.23
+
+package replicate
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the results of a page and all subsequent pages.
+//
+// It fetches pages the same way as Paginate, but only as the iterator advances,
+// so breaking out of the loop stops fetching.
+// If fetching a page fails, the error is yielded and iteration stops.
+func All[T any](ctx context.Context, client *Client, initialPage *Page[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		page := initialPage
+		for {
+			for _, result := range page.Results {
+				if !yield(result, nil) {
+					return
+				}
+			}
+
+			if page.Next == nil {
+				return
+			}
+
+			var err error
+			page, err = page.fetchNext(ctx, client)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// all returns an iterator over every result of a list method.
+func all[T any](ctx context.Context, client *Client, list func(context.Context) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		page, err := list(ctx)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+
+		for result, err := range All(ctx, client, page) {
+			if !yield(result, err) {
+				return
+			}
+		}
+	}
+}
+
+// Predictions returns an iterator over all of your predictions.
+func (r *Client) Predictions(ctx context.Context) iter.Seq2[Prediction, error] {
+	return all(ctx, r, r.ListPredictions)
+}
+
+// Models returns an iterator over all public models.
+func (r *Client) Models(ctx context.Context) iter.Seq2[Model, error] {
+	return all(ctx, r, r.ListModels)
+}
+
+// Trainings returns an iterator over all of your trainings.
+func (r *Client) Trainings(ctx context.Context) iter.Seq2[Training, error] {
+	return all(ctx, r, r.ListTrainings)
+}
+
+// Files returns an iterator over all of your files.
+func (r *Client) Files(ctx context.Context) iter.Seq2[File, error] {
+	return all(ctx, r, r.ListFiles)
+}
+
+// Deployments returns an iterator over all of your deployments.
+func (r *Client) Deployments(ctx context.Context) iter.Seq2[Deployment, error] {
+	return all(ctx, r, r.ListDeployments)
+}
+
+// Collections returns an iterator over all collections.
+func (r *Client) Collections(ctx context.Context) iter.Seq2[Collection, error] {
+	return all(ctx, r, r.ListCollections)
+}
@@ -0,0 +1,120 @@
+//go:build go1.23
+
+package replicate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestPredictionsIterator(t *testing.T) {
+	requestedCursors := []string{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/predictions", r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		cursor := r.URL.Query().Get("cursor")
+		requestedCursors = append(requestedCursors, cursor)
+
+		if cursor == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"detail":"Invalid cursor"}`))
+			return
+		}
+
+		page := replicate.Page[replicate.Prediction]{}
+		switch cursor {
+		case "":
+			next := "/predictions?cursor=2"
+			page.Next = &next
+			page.Results = []replicate.Prediction{{ID: "a"}, {ID: "b"}}
+		case "2":
+			next := "/predictions?cursor=fail"
+			page.Next = &next
+			page.Results = []replicate.Prediction{{ID: "c"}, {ID: "d"}}
+		default:
+			t.Fatalf("unexpected cursor: %s", cursor)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer mockServer.Close()
+
+	client, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithBaseURL(mockServer.URL),
+	)
+	require.NotNil(t, client)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("break", func(t *testing.T) {
+		requestedCursors = nil
+
+		ids := []string{}
+		for prediction, err := range client.Predictions(ctx) {
+			require.NoError(t, err)
+			ids = append(ids, prediction.ID)
+			if len(ids) == 3 {
+				break
+			}
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, ids)
+		assert.Equal(t, []string{"", "2"}, requestedCursors)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		requestedCursors = nil
+
+		ids := []string{}
+		var iterErr error
+		for prediction, err := range client.Predictions(ctx) {
+			if err != nil {
+				iterErr = err
+				continue
+			}
+			ids = append(ids, prediction.ID)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, ids)
+		apiErr := &replicate.APIError{}
+		require.ErrorAs(t, iterErr, &apiErr)
+		assert.Equal(t, "Invalid cursor", apiErr.Detail)
+		assert.Equal(t, []string{"", "2", "fail"}, requestedCursors)
+	})
+}
+
+func ExampleClient_Predictions() {
+	ctx := context.TODO()
+
+	r8, err := replicate.NewClient(replicate.WithTokenFromEnv())
+	if err != nil {
+		panic(err)
+	}
+
+	for prediction, err := range r8.Predictions(ctx) {
+		if err != nil {
+			panic(err)
+		}
+
+		if prediction.Status == replicate.Failed {
+			fmt.Println(prediction.ID)
+			break
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package replicate
+
+// Logger receives messages about what the client is doing,
+// such as the requests it makes and the retries it attempts.
+//
+// Debugf is used for routine events, and Warnf for events
+// that may indicate a problem, like retries and reconnects.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// noopLogger is the default Logger, which discards all messages.
+type noopLogger struct{}
+
+func (noopLogger) Debugf(string, ...interface{}) {}
+func (noopLogger) Warnf(string, ...interface{})  {}
@@ -1,11 +1,25 @@
 package replicate
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"time"
+)
+
+var (
+	ErrNoModelVersions = errors.New("model has no versions")
+
+	ErrModelVersionNotFound  = errors.New("no model version matches prefix")
+	ErrAmbiguousModelVersion = errors.New("more than one model version matches prefix")
+
+	ErrModelVersionHasPredictions = errors.New("model version has predictions")
 )
 
 type Model struct {
@@ -38,14 +52,24 @@ func (m *Model) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Model{}
+
+func (m Model) MarshalJSON() ([]byte, error) {
+	if len(m.rawJSON) > 0 {
+		return m.rawJSON, nil
+	}
+	type Alias Model
+	return json.Marshal(Alias(m))
+}
+
 type CreateModelOptions struct {
-	Visibility    string  `json:"visibility"`
-	Hardware      string  `json:"hardware"`
-	Description   *string `json:"description,omitempty"`
-	GithubURL     *string `json:"github_url,omitempty"`
-	PaperURL      *string `json:"paper_url,omitempty"`
-	LicenseURL    *string `json:"license_url,omitempty"`
-	CoverImageURL *string `json:"cover_image_url,omitempty"`
+	Visibility    string      `json:"visibility"`
+	Hardware      HardwareSKU `json:"hardware"`
+	Description   *string     `json:"description,omitempty"`
+	GithubURL     *string     `json:"github_url,omitempty"`
+	PaperURL      *string     `json:"paper_url,omitempty"`
+	LicenseURL    *string     `json:"license_url,omitempty"`
+	CoverImageURL *string     `json:"cover_image_url,omitempty"`
 }
 
 type ModelVersion struct {
@@ -70,20 +94,136 @@ func (m *ModelVersion) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = ModelVersion{}
+
+func (m ModelVersion) MarshalJSON() ([]byte, error) {
+	if len(m.rawJSON) > 0 {
+		return m.rawJSON, nil
+	}
+	type Alias ModelVersion
+	return json.Marshal(Alias(m))
+}
+
 // ListModels lists public models.
 func (r *Client) ListModels(ctx context.Context) (*Page[Model], error) {
+	return r.ListModelsWithOptions(ctx)
+}
+
+// ModelsSortField is a field that public models can be sorted by.
+type ModelsSortField string
+
+const (
+	ModelsSortByRunCount               ModelsSortField = "run_count"
+	ModelsSortByCreatedAt              ModelsSortField = "model_created_at"
+	ModelsSortByLatestVersionCreatedAt ModelsSortField = "latest_version_created_at"
+)
+
+// ListModelsOption is a function that modifies listModelsOptions.
+type ListModelsOption func(*listModelsOptions)
+
+// listModelsOptions represents filters and ordering for listing models
+type listModelsOptions struct {
+	hardware      []string
+	sortBy        ModelsSortField
+	sortAscending bool
+}
+
+// WithModelsHardware only lists models that can run on the given hardware.
+// It can be given more than once to list models that run on any of them.
+func WithModelsHardware(sku string) ListModelsOption {
+	return func(o *listModelsOptions) {
+		o.hardware = append(o.hardware, sku)
+	}
+}
+
+// WithModelsSortBy sorts the listed models by the given field,
+// in ascending order if ascending is true, or descending order otherwise.
+func WithModelsSortBy(field ModelsSortField, ascending bool) ListModelsOption {
+	return func(o *listModelsOptions) {
+		o.sortBy = field
+		o.sortAscending = ascending
+	}
+}
+
+func (o *listModelsOptions) query() url.Values {
+	query := url.Values{}
+	for _, sku := range o.hardware {
+		query.Add("hardware", sku)
+	}
+	if o.sortBy != "" {
+		query.Set("sort_by", string(o.sortBy))
+		if o.sortAscending {
+			query.Set("sort_direction", "asc")
+		} else {
+			query.Set("sort_direction", "desc")
+		}
+	}
+	return query
+}
+
+// ListModelsWithOptions returns a paginated list of public models, filtered and sorted by the given options.
+//
+// The options are encoded in the next page URL returned by the API,
+// so subsequent pages fetched with Paginate remain filtered.
+func (r *Client) ListModelsWithOptions(ctx context.Context, opts ...ListModelsOption) (*Page[Model], error) {
+	options := &listModelsOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	path := "/models"
+	if query := options.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	response := &Page[Model]{}
+	err := r.fetch(ctx, http.MethodGet, path, nil, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	return response, nil
+}
+
+// ListModelsByOwner lists the models of a user or organization,
+// including private models the client's token has access to.
+func (r *Client) ListModelsByOwner(ctx context.Context, modelOwner string) (*Page[Model], error) {
 	response := &Page[Model]{}
-	err := r.fetch(ctx, http.MethodGet, "/models", nil, response)
+	err := r.fetch(ctx, http.MethodGet, fmt.Sprintf("/models/%s", modelOwner), nil, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list models: %w", err)
 	}
 	return response, nil
 }
 
+// searchMethodQuery is the non-standard HTTP method used to search models.
+const searchMethodQuery = "QUERY"
+
 // SearchModels searches for public models.
+//
+// The search is sent with the QUERY method.
+// Some proxies and transports reject non-standard methods,
+// so if the request fails before a response is received,
+// or the response status is 405 Method Not Allowed,
+// the search is retried with POST.
+// Use WithSearchMethod to always search with POST.
+//
+// The returned page can be passed to Paginate or Collect
+// to fetch the remaining results, using the same method and query.
 func (r *Client) SearchModels(ctx context.Context, query string) (*Page[Model], error) {
+	if r.options.searchMethod == http.MethodPost {
+		return r.searchModels(ctx, http.MethodPost, "/models/search", query)
+	}
+
+	response, err := r.searchModels(ctx, searchMethodQuery, "/models", query)
+	if err != nil && ctx.Err() == nil && shouldFallBackToPost(err) {
+		return r.searchModels(ctx, http.MethodPost, "/models/search", query)
+	}
+	return response, err
+}
+
+func (r *Client) searchModels(ctx context.Context, method string, path string, query string) (*Page[Model], error) {
 	response := &Page[Model]{}
-	request, err := r.newRequest(ctx, "QUERY", "/models", strings.NewReader(query))
+	request, err := r.newRequest(ctx, method, path, strings.NewReader(query))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -92,9 +232,29 @@ func (r *Client) SearchModels(ctx context.Context, query string) (*Page[Model],
 	if err != nil {
 		return nil, fmt.Errorf("failed to search models: %w", err)
 	}
+
+	// Subsequent pages must be requested with the same method and query
+	response.nextPage = func(ctx context.Context, nextURL string) (*Page[Model], error) {
+		return r.searchModels(ctx, method, nextURL, query)
+	}
+
 	return response, nil
 }
 
+// shouldFallBackToPost returns true if a QUERY request failed
+// in a way that suggests the method itself was rejected.
+func shouldFallBackToPost(err error) bool {
+	apiError := &APIError{}
+	if errors.As(err, &apiError) {
+		return apiError.Status == http.StatusMethodNotAllowed
+	}
+	// The request failed before a response was received,
+	// such as when a proxy drops the connection.
+	// Errors decoding or reading a response don't mean the method was rejected.
+	urlError := &url.Error{}
+	return errors.As(err, &urlError)
+}
+
 // GetModel retrieves information about a model.
 func (r *Client) GetModel(ctx context.Context, modelOwner string, modelName string) (*Model, error) {
 	model := &Model{}
@@ -105,6 +265,33 @@ func (r *Client) GetModel(ctx context.Context, modelOwner string, modelName stri
 	return model, nil
 }
 
+// GetModelReadme retrieves the README of a model as Markdown.
+func (r *Client) GetModelReadme(ctx context.Context, modelOwner string, modelName string) (string, error) {
+	request, err := r.newRequest(ctx, http.MethodGet, fmt.Sprintf("/models/%s/%s/readme", modelOwner, modelName), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Accept", "text/markdown")
+
+	readme := &bytes.Buffer{}
+	err = r.do(request, readme)
+	if err != nil {
+		return "", fmt.Errorf("failed to get model readme: %w", err)
+	}
+	return readme.String(), nil
+}
+
+// ListModelExamples lists the example predictions of a model,
+// which show its inputs and outputs.
+func (r *Client) ListModelExamples(ctx context.Context, modelOwner string, modelName string) (*Page[Prediction], error) {
+	response := &Page[Prediction]{}
+	err := r.fetch(ctx, http.MethodGet, fmt.Sprintf("/models/%s/%s/examples", modelOwner, modelName), nil, response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model examples: %w", err)
+	}
+	return response, nil
+}
+
 // CreateModel creates a new model.
 func (r *Client) CreateModel(ctx context.Context, modelOwner string, modelName string, options CreateModelOptions) (*Model, error) {
 	model := &Model{}
@@ -126,6 +313,39 @@ func (r *Client) CreateModel(ctx context.Context, modelOwner string, modelName s
 	return model, nil
 }
 
+// GetOrCreateModel creates a new model, or returns the existing model if one with the same owner and name already exists.
+func (r *Client) GetOrCreateModel(ctx context.Context, modelOwner string, modelName string, options CreateModelOptions) (*Model, error) {
+	model, err := r.CreateModel(ctx, modelOwner, modelName, options)
+	if err == nil {
+		return model, nil
+	}
+
+	if !isAlreadyExistsError(err) {
+		return nil, err
+	}
+
+	return r.GetModel(ctx, modelOwner, modelName)
+}
+
+// isAlreadyExistsError returns true if the error is an API error
+// for a conflict with an existing resource.
+func isAlreadyExistsError(err error) bool {
+	apiError := &APIError{}
+	if !errors.As(err, &apiError) {
+		return false
+	}
+
+	switch apiError.Status {
+	case http.StatusConflict:
+		return true
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		message := strings.ToLower(strings.Join([]string{apiError.Type, apiError.Title, apiError.Detail}, " "))
+		return strings.Contains(message, "already exists")
+	default:
+		return false
+	}
+}
+
 // DeleteModel deletes a model with no associated versions.
 func (r *Client) DeleteModel(ctx context.Context, modelOwner string, modelName string) error {
 	err := r.fetch(ctx, http.MethodDelete, fmt.Sprintf("/models/%s/%s", modelOwner, modelName), nil, nil)
@@ -136,6 +356,36 @@ func (r *Client) DeleteModel(ctx context.Context, modelOwner string, modelName s
 	return nil
 }
 
+// DeleteModelWithVersions deletes all versions of a model, and then the model itself.
+//
+// All versions are listed before any are deleted, so that deleting them doesn't affect pagination.
+// If a version can't be deleted, the remaining versions are still deleted,
+// but the model isn't, and the first error encountered is returned.
+func (r *Client) DeleteModelWithVersions(ctx context.Context, modelOwner string, modelName string) error {
+	page, err := r.ListModelVersions(ctx, modelOwner, modelName)
+	if err != nil {
+		return err
+	}
+
+	versions, err := Collect(ctx, r, page)
+	if err != nil {
+		return fmt.Errorf("failed to list model versions: %w", err)
+	}
+
+	var firstErr error
+	for _, version := range versions {
+		err := r.DeleteModelVersion(ctx, modelOwner, modelName, version.ID)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return r.DeleteModel(ctx, modelOwner, modelName)
+}
+
 // ListModelVersions lists the versions of a model.
 func (r *Client) ListModelVersions(ctx context.Context, modelOwner string, modelName string) (*Page[ModelVersion], error) {
 	response := &Page[ModelVersion]{}
@@ -146,6 +396,52 @@ func (r *Client) ListModelVersions(ctx context.Context, modelOwner string, model
 	return response, nil
 }
 
+// ListAllModelVersions lists every version of a model, newest first.
+//
+// It fetches all pages of results and sorts the versions by creation time.
+func (r *Client) ListAllModelVersions(ctx context.Context, modelOwner string, modelName string) ([]ModelVersion, error) {
+	page, err := r.ListModelVersions(ctx, modelOwner, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := Collect(ctx, r, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model versions: %w", err)
+	}
+
+	createdAt := make(map[string]time.Time, len(versions))
+	for _, version := range versions {
+		t, err := version.CreatedAtTime()
+		if err != nil {
+			return nil, fmt.Errorf("model version %s: %w", version.ID, err)
+		}
+		createdAt[version.ID] = t
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return createdAt[versions[i].ID].After(createdAt[versions[j].ID])
+	})
+
+	return versions, nil
+}
+
+// LatestVersion retrieves the most recently created version of a model.
+//
+// If the model has no versions, an error wrapping ErrNoModelVersions is returned.
+func (r *Client) LatestVersion(ctx context.Context, modelOwner string, modelName string) (*ModelVersion, error) {
+	versions, err := r.ListAllModelVersions(ctx, modelOwner, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNoModelVersions, modelOwner, modelName)
+	}
+
+	return &versions[0], nil
+}
+
 // GetModelVersion retrieves a specific version of a model.
 func (r *Client) GetModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string) (*ModelVersion, error) {
 	version := &ModelVersion{}
@@ -156,28 +452,147 @@ func (r *Client) GetModelVersion(ctx context.Context, modelOwner string, modelNa
 	return version, nil
 }
 
+// GetModelVersionByPrefix retrieves the version of a model whose ID starts with prefix,
+// like resolving a short commit hash in git.
+//
+// If no version matches, an error wrapping ErrModelVersionNotFound is returned.
+// If more than one version matches, an error wrapping ErrAmbiguousModelVersion is returned,
+// and a longer prefix is needed.
+func (r *Client) GetModelVersionByPrefix(ctx context.Context, modelOwner string, modelName string, prefix string) (*ModelVersion, error) {
+	if prefix == "" {
+		return nil, errors.New("version prefix must not be empty")
+	}
+
+	page, err := r.ListModelVersions(ctx, modelOwner, modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := Collect(ctx, r, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list model versions: %w", err)
+	}
+
+	var matches []ModelVersion
+	for _, version := range versions {
+		if strings.HasPrefix(version.ID, prefix) {
+			matches = append(matches, version)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s/%s:%s", ErrModelVersionNotFound, modelOwner, modelName, prefix)
+	case 1:
+		return &matches[0], nil
+	default:
+		ids := make([]string, len(matches))
+		for i, version := range matches {
+			ids[i] = version.ID
+		}
+		return nil, fmt.Errorf("%w: %s/%s:%s matches %s", ErrAmbiguousModelVersion, modelOwner, modelName, prefix, strings.Join(ids, ", "))
+	}
+}
+
+// DeleteModelVersionOption is a function that modifies the options of DeleteModelVersion.
+type DeleteModelVersionOption func(*deleteModelVersionOptions)
+
+type deleteModelVersionOptions struct {
+	confirmIfPredictionsExist bool
+}
+
+// WithConfirmIfPredictionsExist checks for your predictions of the version before deleting it.
+// If there are any, the version isn't deleted, and an error wrapping ErrModelVersionHasPredictions is returned,
+// so they're only deleted if you call DeleteModelVersion again without this option.
+//
+// The check only covers your own most recent predictions:
+// it lists at most 10 pages of them, newest first.
+// Predictions made by other users of the version,
+// and older predictions of your own, aren't checked,
+// so the version may still have predictions that are deleted with it.
+func WithConfirmIfPredictionsExist() DeleteModelVersionOption {
+	return func(o *deleteModelVersionOptions) {
+		o.confirmIfPredictionsExist = true
+	}
+}
+
 // DeleteModelVersion deletes a model version and all associated predictions, including all output files.
-func (r *Client) DeleteModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string) error {
-	err := r.fetch(ctx, http.MethodDelete, fmt.Sprintf("/models/%s/%s/versions/%s", modelOwner, modelName, versionID), nil, nil)
+// This can't be undone.
+// Use WithConfirmIfPredictionsExist to avoid deleting predictions by accident.
+func (r *Client) DeleteModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string, opts ...DeleteModelVersionOption) error {
+	_, err := r.DeleteModelVersionWithResult(ctx, modelOwner, modelName, versionID, opts...)
+	return err
+}
+
+// DeleteModelVersionWithResult deletes a model version, and returns the API's response.
+// Versions may be deleted asynchronously, in which case the result is Accepted.
+//
+// Like DeleteModelVersion, it also deletes all associated predictions and their output files.
+func (r *Client) DeleteModelVersionWithResult(ctx context.Context, modelOwner string, modelName string, versionID string, opts ...DeleteModelVersionOption) (*DeleteResult, error) {
+	options := deleteModelVersionOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.confirmIfPredictionsExist {
+		found, err := r.hasModelVersionPredictions(ctx, versionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for model version predictions: %w", err)
+		}
+		if found {
+			return nil, fmt.Errorf("%w: %s/%s:%s", ErrModelVersionHasPredictions, modelOwner, modelName, versionID)
+		}
+	}
+
+	result := &DeleteResult{}
+	err := r.fetch(ctx, http.MethodDelete, fmt.Sprintf("/models/%s/%s/versions/%s", modelOwner, modelName, versionID), nil, deleteResultWriter{result})
 	if err != nil {
-		return fmt.Errorf("failed to delete model version: %w", err)
+		return nil, fmt.Errorf("failed to delete model version: %w", err)
 	}
-	return nil
+	return result, nil
 }
 
-// CreatePredictionWithModel sends a request to the Replicate API to create a prediction for a model.
-func (r *Client) CreatePredictionWithModel(ctx context.Context, modelOwner string, modelName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error) {
-	path := fmt.Sprintf("/models/%s/%s/predictions", modelOwner, modelName)
+// maxVersionPredictionsPages is the number of pages of predictions
+// hasModelVersionPredictions lists before giving up.
+const maxVersionPredictionsPages = 10
 
-	req, err := r.createPredictionRequest(ctx, path, nil, input, webhook, stream)
+// hasModelVersionPredictions reports whether any of your recent predictions use a model version,
+// stopping at the first page that has one, or after maxVersionPredictionsPages pages.
+func (r *Client) hasModelVersionPredictions(ctx context.Context, versionID string) (bool, error) {
+	page, err := r.ListPredictionsWithOptions(ctx, WithListPredictionFields("id", "version"))
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	prediction := &Prediction{}
-	if err := r.do(req, prediction); err != nil {
-		return nil, fmt.Errorf("failed to create prediction with model: %w", err)
+	for pages := 1; ; pages++ {
+		for _, prediction := range page.Results {
+			if prediction.Version == versionID {
+				return true, nil
+			}
+		}
+
+		if page.Next == nil || pages >= maxVersionPredictionsPages {
+			return false, nil
+		}
+
+		page, err = page.fetchNext(ctx, r)
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// CreatePredictionWithModel sends a request to the Replicate API to create a prediction for a model.
+func (r *Client) CreatePredictionWithModel(ctx context.Context, modelOwner string, modelName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error) {
+	return r.CreatePredictionWithOptions(ctx, positionalPredictionOptions(WithPredictionModel(modelOwner, modelName), input, webhook, stream)...)
+}
+
+// CreatePredictionWithVersion creates a prediction for a model version,
+// such as one returned by GetModelVersion.
+func (r *Client) CreatePredictionWithVersion(ctx context.Context, version *ModelVersion, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error) {
+	if version == nil || version.ID == "" {
+		return nil, errors.New("model version must have an ID")
 	}
 
-	return prediction, nil
+	return r.CreatePrediction(ctx, version.ID, input, webhook, stream)
 }
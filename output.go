@@ -0,0 +1,63 @@
+package replicate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNoOutput = errors.New("prediction has no output")
+)
+
+// OutputAs converts a prediction output to a value of type T.
+//
+// The output is converted by encoding it as JSON and decoding it into T,
+// so T can be any type that the output's JSON can be unmarshaled into,
+// such as a struct with json tags.
+// If the output is nil, an error wrapping ErrNoOutput is returned.
+func OutputAs[T any](output PredictionOutput) (T, error) {
+	var value T
+	if output == nil {
+		return value, ErrNoOutput
+	}
+
+	if v, ok := output.(T); ok {
+		return v, nil
+	}
+
+	data, err := json.Marshal(output)
+	if err != nil {
+		return value, fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	err = json.Unmarshal(data, &value)
+	if err != nil {
+		return value, fmt.Errorf("failed to convert output to %T: %w", value, err)
+	}
+
+	return value, nil
+}
+
+// OutputAsStrings converts a prediction output to a slice of strings,
+// such as a list of file URLs.
+func OutputAsStrings(output PredictionOutput) ([]string, error) {
+	return OutputAs[[]string](output)
+}
+
+// OutputAsString converts a prediction output to a string.
+//
+// Language models output a list of tokens,
+// so a list of strings is joined into a single string.
+func OutputAsString(output PredictionOutput) (string, error) {
+	if s, ok := output.(string); ok {
+		return s, nil
+	}
+
+	if tokens, err := OutputAsStrings(output); err == nil {
+		return strings.Join(tokens, ""), nil
+	}
+
+	return OutputAs[string](output)
+}
@@ -0,0 +1,106 @@
+package replicate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestOutputAs(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		type segment struct {
+			Start float64 `json:"start"`
+			Text  string  `json:"text"`
+		}
+		type transcription struct {
+			Language string    `json:"language"`
+			Segments []segment `json:"segments"`
+		}
+
+		output := replicate.PredictionOutput(map[string]interface{}{
+			"language": "en",
+			"segments": []interface{}{
+				map[string]interface{}{"start": 0.5, "text": "Hello"},
+			},
+		})
+
+		value, err := replicate.OutputAs[transcription](output)
+		require.NoError(t, err)
+		assert.Equal(t, transcription{Language: "en", Segments: []segment{{Start: 0.5, Text: "Hello"}}}, value)
+	})
+
+	t.Run("mismatched type", func(t *testing.T) {
+		_, err := replicate.OutputAs[int](replicate.PredictionOutput("hello"))
+		assert.Error(t, err)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := replicate.OutputAs[string](nil)
+		assert.ErrorIs(t, err, replicate.ErrNoOutput)
+	})
+}
+
+func TestOutputAsStrings(t *testing.T) {
+	output := replicate.PredictionOutput([]interface{}{"https://example.com/0.png", "https://example.com/1.png"})
+
+	urls, err := replicate.OutputAsStrings(output)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/0.png", "https://example.com/1.png"}, urls)
+
+	_, err = replicate.OutputAsStrings(replicate.PredictionOutput([]interface{}{1, 2}))
+	assert.Error(t, err)
+}
+
+func TestOutputAsString(t *testing.T) {
+	s, err := replicate.OutputAsString(replicate.PredictionOutput("Hello, Alice"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, Alice", s)
+
+	s, err = replicate.OutputAsString(replicate.PredictionOutput([]interface{}{"Hello", ", ", "Alice"}))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, Alice", s)
+
+	_, err = replicate.OutputAsString(replicate.PredictionOutput(map[string]interface{}{"text": "Hello"}))
+	assert.Error(t, err)
+
+	_, err = replicate.OutputAsString(nil)
+	assert.ErrorIs(t, err, replicate.ErrNoOutput)
+}
+
+func TestPredictionFinalOutput(t *testing.T) {
+	t.Run("not finished", func(t *testing.T) {
+		for _, status := range []replicate.Status{replicate.Starting, replicate.Processing} {
+			// A partial output of a streamed JSON document
+			p := replicate.Prediction{ID: "ufawqhfynnddngldkgtslldrkq", Status: status, Output: map[string]interface{}{"name": "Al"}}
+			output, err := p.FinalOutput()
+			assert.ErrorIs(t, err, replicate.ErrPredictionNotFinished)
+			assert.Nil(t, output)
+		}
+	})
+
+	t.Run("succeeded", func(t *testing.T) {
+		p := replicate.Prediction{Status: replicate.Succeeded, Output: map[string]interface{}{"name": "Alice"}}
+		output, err := p.FinalOutput()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": "Alice"}, output)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		p := replicate.Prediction{Status: replicate.Failed, Error: "CUDA out of memory"}
+		output, err := p.FinalOutput()
+		var modelErr *replicate.ModelError
+		require.ErrorAs(t, err, &modelErr)
+		assert.Equal(t, "CUDA out of memory", modelErr.Prediction.ErrorString())
+		assert.Nil(t, output)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		p := replicate.Prediction{Status: replicate.Canceled, Output: []interface{}{"Hello"}}
+		output, err := p.FinalOutput()
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"Hello"}, output)
+	})
+}
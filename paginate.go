@@ -13,6 +13,10 @@ type Page[T any] struct {
 	Results  []T     `json:"results"`
 
 	rawJSON json.RawMessage `json:"-"`
+
+	// nextPage fetches the page at the next URL.
+	// It's set for results that can't be fetched with GET, like search results.
+	nextPage func(ctx context.Context, nextURL string) (*Page[T], error)
 }
 
 func (p *Page[T]) RawJSON() json.RawMessage {
@@ -28,6 +32,16 @@ func (p *Page[T]) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Page[Prediction]{}
+
+func (p Page[T]) MarshalJSON() ([]byte, error) {
+	if len(p.rawJSON) > 0 {
+		return p.rawJSON, nil
+	}
+	type Alias Page[T]
+	return json.Marshal(Alias(p))
+}
+
 // Paginate takes a Page and the Client request method, and iterates through pages of results.
 func Paginate[T any](ctx context.Context, client *Client, initialPage *Page[T]) (<-chan []T, <-chan error) {
 	resultsChan := make(chan []T)
@@ -38,21 +52,62 @@ func Paginate[T any](ctx context.Context, client *Client, initialPage *Page[T])
 		defer close(errChan)
 
 		resultsChan <- initialPage.Results
-		nextURL := initialPage.Next
 
-		for nextURL != nil {
-			page := &Page[T]{}
-			err := client.fetch(ctx, http.MethodGet, *nextURL, nil, page)
+		page := initialPage
+		for page.Next != nil {
+			var err error
+			page, err = page.fetchNext(ctx, client)
 			if err != nil {
 				errChan <- err
 				return
 			}
 
 			resultsChan <- page.Results
-
-			nextURL = page.Next
 		}
 	}()
 
 	return resultsChan, errChan
 }
+
+// fetchNext fetches the page at the next URL.
+func (p *Page[T]) fetchNext(ctx context.Context, client *Client) (*Page[T], error) {
+	if p.nextPage != nil {
+		return p.nextPage(ctx, *p.Next)
+	}
+
+	page := &Page[T]{}
+	err := client.fetch(ctx, http.MethodGet, *p.Next, nil, page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// Collect takes a Page and fetches all subsequent pages,
+// returning the results of every page in a single slice.
+// If fetching a page fails, the error is returned.
+func Collect[T any](ctx context.Context, client *Client, initialPage *Page[T]) ([]T, error) {
+	var all []T
+
+	resultsChan, errChan := Paginate(ctx, client, initialPage)
+	for resultsChan != nil {
+		select {
+		case results, ok := <-resultsChan:
+			if !ok {
+				resultsChan = nil
+				continue
+			}
+			all = append(all, results...)
+		case err, ok := <-errChan:
+			if !ok {
+				errChan = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return all, nil
+}
@@ -4,10 +4,20 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/http"
-	"regexp"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrPredictionNotFinished = errors.New("prediction has not finished")
 )
 
 type Source string
@@ -17,6 +27,23 @@ const (
 	SourceAPI Source = "api"
 )
 
+// IsValid returns true if the source is one of the known sources.
+// Predictions from the API may have sources this client doesn't know about yet,
+// so they're unmarshaled as they are.
+func (s Source) IsValid() bool {
+	switch s {
+	case SourceWeb, SourceAPI:
+		return true
+	default:
+		return false
+	}
+}
+
+// Prediction is a run of a model.
+//
+// Output is the output so far, so until the status is terminated,
+// it may be incomplete, such as a partial JSON structure.
+// Use FinalOutput to only get the output of a finished prediction.
 type Prediction struct {
 	ID                  string             `json:"id"`
 	Status              Status             `json:"status"`
@@ -51,6 +78,60 @@ func (p *Prediction) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = Prediction{}
+
+// MarshalJSON returns the JSON the prediction was unmarshaled from, if any,
+// so that fields added to the API after this client was built are preserved
+// when a prediction is forwarded. Otherwise, it marshals the struct's fields.
+//
+// Changes made to a prediction's fields after unmarshaling it aren't reflected in the output.
+func (p Prediction) MarshalJSON() ([]byte, error) {
+	if len(p.rawJSON) > 0 {
+		return p.rawJSON, nil
+	}
+	type Alias Prediction
+	return json.Marshal(Alias(p))
+}
+
+// StreamURL returns the URL for streaming the prediction's output,
+// and whether the prediction has one.
+// Predictions only have a stream URL if they were created with streaming enabled,
+// for a model that supports it.
+func (p *Prediction) StreamURL() (string, bool) {
+	url, ok := p.URLs["stream"]
+	return url, ok && url != ""
+}
+
+// PredictionError returns the typed error of a failed prediction, or nil if there is no error.
+func (p Prediction) PredictionError() *PredictionError {
+	return newPredictionError(p.Error)
+}
+
+// ErrorString returns the error message of a failed prediction, or an empty string if there is no error.
+func (p Prediction) ErrorString() string {
+	predictionError := p.PredictionError()
+	if predictionError == nil {
+		return ""
+	}
+	return predictionError.Message
+}
+
+// FinalOutput returns the output of a finished prediction.
+//
+// If the prediction hasn't finished, it returns an error wrapping ErrPredictionNotFinished,
+// since the output may be incomplete.
+// If the prediction failed, it returns a *ModelError.
+// A canceled prediction's output is what it produced before it was canceled.
+func (p Prediction) FinalOutput() (PredictionOutput, error) {
+	if !p.Status.Terminated() {
+		return nil, fmt.Errorf("%w: prediction %s is %s", ErrPredictionNotFinished, p.ID, p.Status)
+	}
+	if p.Error != nil {
+		return nil, &ModelError{Prediction: &p}
+	}
+	return p.Output, nil
+}
+
 type PredictionInput map[string]interface{}
 type PredictionOutput interface{}
 
@@ -69,107 +150,408 @@ type PredictionProgress struct {
 	Total      int
 }
 
-func (p Prediction) Progress() *PredictionProgress {
+// Progress returns the most recent progress reported in the prediction's logs,
+// or nil if there is none.
+//
+// By default, it recognizes tqdm progress bars.
+// Use WithProgressParser to recognize other formats.
+func (p Prediction) Progress(opts ...ProgressOption) *PredictionProgress {
 	if p.Logs == nil || *p.Logs == "" {
 		return nil
 	}
 
-	pattern := `^\s*(?P<percentage>\d+)%\s*\|.+?\|\s*(?P<current>\d+)\/(?P<total>\d+)`
-	re := regexp.MustCompile(pattern)
-
-	lines := strings.Split(*p.Logs, "\n")
-	if len(lines) == 0 {
-		return nil
+	options := &progressOptions{
+		parsers: []ProgressParser{ParseTqdmProgress},
+	}
+	for _, opt := range opts {
+		opt(options)
 	}
 
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if re.MatchString(line) {
-			matches := re.FindStringSubmatch(lines[i])
-			if len(matches) == 4 {
-				var percentage, current, total int
-				fmt.Sscanf(matches[1], "%d", &percentage)
-				fmt.Sscanf(matches[2], "%d", &current)
-				fmt.Sscanf(matches[3], "%d", &total)
-				return &PredictionProgress{
-					Percentage: float64(percentage) / float64(100),
-					Current:    current,
-					Total:      total,
-				}
-			}
+	for _, parser := range options.parsers {
+		if progress := parser(*p.Logs); progress != nil {
+			return progress
 		}
 	}
 
 	return nil
 }
 
+// CreatePredictionOption is a function that modifies createPredictionOptions.
+type CreatePredictionOption func(*createPredictionOptions)
+
+// createPredictionOptions represents options for creating a prediction
+type createPredictionOptions struct {
+	modelOwner      string
+	modelName       string
+	version         string
+	deploymentOwner string
+	deploymentName  string
+
+	input   PredictionInput
+	webhook *Webhook
+	stream  bool
+
+	wait        bool
+	waitTimeout int
+
+	idempotencyKey string
+
+	skipFileConversion bool
+
+	// dryRun replaces files to upload with a placeholder instead of uploading them.
+	dryRun bool
+}
+
+// WithPredictionModel creates the prediction with the latest version of an official model.
+func WithPredictionModel(modelOwner string, modelName string) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.modelOwner = modelOwner
+		o.modelName = modelName
+	}
+}
+
+// WithPredictionVersion creates the prediction with a specific version of a model.
+func WithPredictionVersion(version string) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.version = version
+	}
+}
+
+// WithPredictionDeployment creates the prediction with a deployment.
+func WithPredictionDeployment(deploymentOwner string, deploymentName string) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.deploymentOwner = deploymentOwner
+		o.deploymentName = deploymentName
+	}
+}
+
+// WithPredictionInput sets the input of the prediction.
+func WithPredictionInput(input PredictionInput) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.input = input
+	}
+}
+
+// WithPredictionWebhook sets the webhook called as the prediction progresses.
+// If webhook is nil, the client's default webhook is used.
+func WithPredictionWebhook(webhook *Webhook) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.webhook = webhook
+	}
+}
+
+// WithPredictionStream requests a URL for streaming the prediction's output.
+func WithPredictionStream() CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.stream = true
+	}
+}
+
+// WithPredictionWait holds the connection open until the prediction is done, or the API's time limit is reached.
+// The returned prediction may still be running, so check its status before using its output.
+func WithPredictionWait() CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.wait = true
+	}
+}
+
+// WithPredictionWaitTimeout holds the connection open until the prediction is done,
+// for at most the given number of seconds.
+// The timeout is clamped between 1 and 60 seconds.
+// The returned prediction may still be running, so check its status before using its output.
+func WithPredictionWaitTimeout(seconds int) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.wait = true
+		o.waitTimeout = min(max(seconds, 1), maxBlockTimeout)
+	}
+}
+
+// WithPredictionIdempotencyKey sets the Idempotency-Key header,
+// so that retrying the request doesn't create a duplicate prediction.
+// This includes retries made automatically by the client.
+//
+// It only applies to requests that create a prediction.
+func WithPredictionIdempotencyKey(key string) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithPredictionFileConversion sets whether *File values in the input are
+// replaced with their "get" URL, which is the default.
+// Pass false to send them as they are, to pass the file's URL,
+// or another representation of it, yourself.
+// FileRef and io.Reader values are uploaded either way.
+func WithPredictionFileConversion(convert bool) CreatePredictionOption {
+	return func(o *createPredictionOptions) {
+		o.skipFileConversion = !convert
+	}
+}
+
+// path returns the endpoint for creating the prediction with the chosen model, version, or deployment.
+func (o *createPredictionOptions) path() (string, error) {
+	targets := 0
+	path := ""
+	if o.version != "" {
+		targets++
+		path = "/predictions"
+	}
+	if o.modelOwner != "" || o.modelName != "" {
+		targets++
+		path = fmt.Sprintf("/models/%s/%s/predictions", o.modelOwner, o.modelName)
+	}
+	if o.deploymentOwner != "" || o.deploymentName != "" {
+		targets++
+		path = fmt.Sprintf("/deployments/%s/%s/predictions", o.deploymentOwner, o.deploymentName)
+	}
+
+	if targets != 1 {
+		return "", errors.New("exactly one of WithPredictionModel, WithPredictionVersion, or WithPredictionDeployment must be set")
+	}
+
+	return path, nil
+}
+
 // createPredictionRequest creates a prediction request.
-func (r *Client) createPredictionRequest(ctx context.Context, path string, data map[string]interface{}, input PredictionInput, webhook *Webhook, stream bool) (*http.Request, error) {
-	// Convert File objects in input to their "get" URL value
+func (r *Client) createPredictionRequest(ctx context.Context, options *createPredictionOptions) (*http.Request, error) {
+	path, err := options.path()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := r.webhookOrDefault(options.webhook)
+	if webhook != nil {
+		if err := webhook.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid webhook: %w", err)
+		}
+	}
+
+	// Work on a copy, so the caller's input can be submitted again
+	input := maps.Clone(options.input)
+
+	// Convert File objects in input to their "get" URL value,
+	// uploading FileRef and io.Reader values first
 	for key, value := range input {
 		if file, ok := value.(*File); ok {
-			input[key] = file.URLs["get"]
+			if !options.skipFileConversion {
+				input[key] = file.URLs["get"]
+			}
+			continue
 		}
-	}
 
-	if data == nil {
-		data = make(map[string]interface{})
+		if options.dryRun {
+			if ref, ok := inputFileRef(value); ok {
+				input[key] = uploadPlaceholder(ref)
+			}
+			continue
+		}
+
+		url, ok, err := r.uploadInputFile(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload input %q: %w", key, err)
+		}
+		if ok {
+			input[key] = url
+		}
 	}
 
-	data["input"] = input
+	data := map[string]interface{}{
+		"input": input,
+	}
 
-	if webhook != nil {
-		data["webhook"] = webhook.URL
-		if len(webhook.Events) > 0 {
-			data["webhook_events_filter"] = webhook.Events
-		}
+	if options.version != "" {
+		data["version"] = options.version
 	}
 
-	if stream {
+	webhook.addToRequestBody(data)
+
+	if options.stream {
 		data["stream"] = true
 	}
 
-	bodyBuffer := &bytes.Buffer{}
-	if data != nil {
-		bodyBytes, err := json.Marshal(data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		bodyBuffer = bytes.NewBuffer(bodyBytes)
+	bodyBytes, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := r.newRequest(ctx, http.MethodPost, path, bodyBuffer)
+	req, err := r.newRequest(ctx, http.MethodPost, path, bytes.NewBuffer(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prediction request: %w", err)
 	}
 
+	if options.wait {
+		if options.waitTimeout > 0 {
+			req.Header.Set("Prefer", fmt.Sprintf("wait=%d", options.waitTimeout))
+		} else {
+			req.Header.Set("Prefer", "wait")
+		}
+	}
+
+	if options.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", options.idempotencyKey)
+	}
+
 	return req, nil
 }
 
-// CreatePrediction creates a prediction for a specific version of a model.
-func (r *Client) CreatePrediction(ctx context.Context, version string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error) {
-	path := "/predictions"
-	data := map[string]interface{}{
-		"version": version,
+// uploadPlaceholder returns the value that stands in for a file to upload
+// in a request built by BuildCreatePredictionRequest.
+func uploadPlaceholder(ref FileRef) string {
+	if ref.Filename == "" {
+		return "<upload>"
+	}
+	return fmt.Sprintf("<upload: %s>", ref.Filename)
+}
+
+// BuildCreatePredictionRequest returns the request that CreatePredictionWithOptions
+// would send for the given options, without sending it.
+// It can be used to log or inspect the URL, headers, and JSON body of the request.
+//
+// The Authorization header is removed so the request can be logged without exposing the token.
+// Inputs that are files to upload, such as FileRef and io.Reader values, aren't uploaded.
+// They're replaced in the body with a placeholder like "<upload: image.png>",
+// so the request can't be sent as it is.
+func (r *Client) BuildCreatePredictionRequest(ctx context.Context, opts ...CreatePredictionOption) (*http.Request, error) {
+	options := &createPredictionOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	options.dryRun = true
+
+	req, err := r.createPredictionRequest(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Del("Authorization")
+
+	return req, nil
+}
+
+// CreatePredictionWithOptions creates a prediction.
+//
+// Exactly one of WithPredictionModel, WithPredictionVersion, or WithPredictionDeployment
+// must be given to choose what runs the prediction.
+func (r *Client) CreatePredictionWithOptions(ctx context.Context, opts ...CreatePredictionOption) (*Prediction, error) {
+	options := &createPredictionOptions{}
+	for _, opt := range opts {
+		opt(options)
 	}
 
-	req, err := r.createPredictionRequest(ctx, path, data, input, webhook, stream)
+	req, err := r.createPredictionRequest(ctx, options)
 	if err != nil {
 		return nil, err
 	}
 
 	prediction := &Prediction{}
 	if err := r.do(req, prediction); err != nil {
-		return nil, fmt.Errorf("failed to create prediction: %w", err)
+		switch {
+		case options.modelOwner != "" || options.modelName != "":
+			return nil, fmt.Errorf("failed to create prediction with model: %w", err)
+		case options.deploymentOwner != "" || options.deploymentName != "":
+			return nil, fmt.Errorf("failed to create prediction with deployment: %w", err)
+		default:
+			return nil, fmt.Errorf("failed to create prediction: %w", err)
+		}
 	}
 
 	return prediction, nil
 }
 
+// positionalPredictionOptions converts the positional arguments of the
+// CreatePrediction methods to options.
+func positionalPredictionOptions(target CreatePredictionOption, input PredictionInput, webhook *Webhook, stream bool) []CreatePredictionOption {
+	options := []CreatePredictionOption{target, WithPredictionInput(input)}
+	if webhook != nil {
+		options = append(options, WithPredictionWebhook(webhook))
+	}
+	if stream {
+		options = append(options, WithPredictionStream())
+	}
+	return options
+}
+
+// CreatePrediction creates a prediction for a specific version of a model.
+func (r *Client) CreatePrediction(ctx context.Context, version string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error) {
+	return r.CreatePredictionWithOptions(ctx, positionalPredictionOptions(WithPredictionVersion(version), input, webhook, stream)...)
+}
+
+// ListPredictionOption is a function that modifies listPredictionOptions.
+type ListPredictionOption func(*listPredictionOptions)
+
+// listPredictionOptions represents filters for listing predictions
+type listPredictionOptions struct {
+	status        *Status
+	createdAfter  *time.Time
+	createdBefore *time.Time
+	fields        []string
+}
+
+// WithStatusFilter only lists predictions with the given status.
+func WithStatusFilter(status Status) ListPredictionOption {
+	return func(o *listPredictionOptions) {
+		o.status = &status
+	}
+}
+
+// WithCreatedAfter only lists predictions created after the given time.
+func WithCreatedAfter(t time.Time) ListPredictionOption {
+	return func(o *listPredictionOptions) {
+		o.createdAfter = &t
+	}
+}
+
+// WithCreatedBefore only lists predictions created before the given time.
+func WithCreatedBefore(t time.Time) ListPredictionOption {
+	return func(o *listPredictionOptions) {
+		o.createdBefore = &t
+	}
+}
+
+// WithListPredictionFields only includes the given fields in the listed predictions,
+// such as "id", "status", and "output".
+// Fields that aren't included are left as their zero value.
+func WithListPredictionFields(fields ...string) ListPredictionOption {
+	return func(o *listPredictionOptions) {
+		o.fields = append(o.fields, fields...)
+	}
+}
+
+func (o *listPredictionOptions) query() url.Values {
+	query := fieldsQuery(o.fields)
+	if o.status != nil {
+		query.Set("status", o.status.String())
+	}
+	if o.createdAfter != nil {
+		query.Set("created_after", o.createdAfter.UTC().Format(time.RFC3339))
+	}
+	if o.createdBefore != nil {
+		query.Set("created_before", o.createdBefore.UTC().Format(time.RFC3339))
+	}
+	return query
+}
+
 // ListPredictions returns a paginated list of predictions.
 func (r *Client) ListPredictions(ctx context.Context) (*Page[Prediction], error) {
+	return r.ListPredictionsWithOptions(ctx)
+}
+
+// ListPredictionsWithOptions returns a paginated list of predictions matching the given filters.
+//
+// The filters are encoded in the next page URL returned by the API,
+// so subsequent pages fetched with Paginate remain filtered.
+func (r *Client) ListPredictionsWithOptions(ctx context.Context, opts ...ListPredictionOption) (*Page[Prediction], error) {
+	options := &listPredictionOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	path := "/predictions"
+	if query := options.query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
 	response := &Page[Prediction]{}
-	err := r.fetch(ctx, http.MethodGet, "/predictions", nil, response)
+	err := r.fetch(ctx, http.MethodGet, path, nil, response)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list predictions: %w", err)
 	}
@@ -178,20 +560,167 @@ func (r *Client) ListPredictions(ctx context.Context) (*Page[Prediction], error)
 
 // GetPrediction retrieves a prediction from the Replicate API by its ID.
 func (r *Client) GetPrediction(ctx context.Context, id string) (*Prediction, error) {
+	return r.GetPredictionWithOptions(ctx, id)
+}
+
+// GetPredictionOption is a function that modifies getPredictionOptions.
+type GetPredictionOption func(*getPredictionOptions)
+
+// getPredictionOptions represents options for retrieving a prediction
+type getPredictionOptions struct {
+	fields []string
+}
+
+// WithPredictionFields only includes the given fields in the returned prediction,
+// such as "id", "status", and "output".
+// Fields that aren't included are left as their zero value.
+// Leaving out "logs" makes polling a long-running prediction much cheaper.
+func WithPredictionFields(fields ...string) GetPredictionOption {
+	return func(o *getPredictionOptions) {
+		o.fields = append(o.fields, fields...)
+	}
+}
+
+// fieldsQuery returns the query parameters for the fields to include.
+func fieldsQuery(fields []string) url.Values {
+	query := url.Values{}
+	if len(fields) > 0 {
+		query.Set("include", strings.Join(fields, ","))
+	}
+	return query
+}
+
+// GetPredictionWithOptions retrieves a prediction, with the given options.
+func (r *Client) GetPredictionWithOptions(ctx context.Context, id string, opts ...GetPredictionOption) (*Prediction, error) {
+	options := &getPredictionOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	path := fmt.Sprintf("/predictions/%s", id)
+	if query := fieldsQuery(options.fields); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
 	prediction := &Prediction{}
-	err := r.fetch(ctx, http.MethodGet, fmt.Sprintf("/predictions/%s", id), nil, prediction)
+	err := r.fetch(ctx, http.MethodGet, path, nil, prediction)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prediction: %w", err)
 	}
 	return prediction, nil
 }
 
+const defaultCancelConcurrency = 8
+
+// CancelPredictionsOption is a function that modifies cancelPredictionsOptions.
+type CancelPredictionsOption func(*cancelPredictionsOptions)
+
+// cancelPredictionsOptions represents options for canceling predictions in bulk
+type cancelPredictionsOptions struct {
+	concurrency int
+}
+
+// WithCancelConcurrency sets the maximum number of cancel requests in flight at once.
+func WithCancelConcurrency(concurrency int) CancelPredictionsOption {
+	return func(o *cancelPredictionsOptions) {
+		o.concurrency = concurrency
+	}
+}
+
+// CancelPredictions cancels many predictions concurrently.
+//
+// It returns a map from each prediction ID to the error from canceling it, or nil on success.
+// If the context is canceled, no new cancel requests are sent:
+// the remaining IDs are mapped to the context's error, which is also returned.
+func (r *Client) CancelPredictions(ctx context.Context, ids []string, opts ...CancelPredictionsOption) (map[string]error, error) {
+	options := &cancelPredictionsOptions{
+		concurrency: defaultCancelConcurrency,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if options.concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1: %d", options.concurrency)
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]error, len(ids))
+	setResult := func(id string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		results[id] = err
+	}
+
+	g := &errgroup.Group{}
+	g.SetLimit(options.concurrency)
+	for _, id := range ids {
+		id := id
+		if err := ctx.Err(); err != nil {
+			setResult(id, err)
+			continue
+		}
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				setResult(id, err)
+				return nil
+			}
+			_, err := r.CancelPrediction(ctx, id)
+			setResult(id, err)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return results, ctx.Err()
+}
+
+// ReloadPrediction refreshes the state of a prediction in place.
+//
+// Unlike GetPrediction, it updates the given prediction,
+// so references to it held elsewhere observe the new state.
+func (r *Client) ReloadPrediction(ctx context.Context, prediction *Prediction) error {
+	if prediction == nil || prediction.ID == "" {
+		return errors.New("failed to reload prediction: prediction has no ID")
+	}
+
+	updatedPrediction, err := r.GetPrediction(ctx, prediction.ID)
+	if err != nil {
+		return err
+	}
+
+	*prediction = *updatedPrediction
+	return nil
+}
+
 // CancelPrediction cancels a running prediction by its ID.
+//
+// Canceling a prediction that has already finished isn't an error:
+// the prediction is returned with its terminal status, which may be other than canceled.
+// If the API rejects the request as invalid or conflicting,
+// the prediction is fetched to check whether it has already finished.
 func (r *Client) CancelPrediction(ctx context.Context, id string) (*Prediction, error) {
 	prediction := &Prediction{}
 	err := r.fetch(ctx, http.MethodPost, fmt.Sprintf("/predictions/%s/cancel", id), nil, prediction)
 	if err != nil {
+		apiError := &APIError{}
+		if errors.As(err, &apiError) && isAlreadyTerminalStatus(apiError.Status) {
+			current, getErr := r.GetPrediction(ctx, id)
+			if getErr == nil && current.Status.Terminated() {
+				return current, nil
+			}
+		}
 		return nil, fmt.Errorf("failed to cancel prediction: %w", err)
 	}
 	return prediction, nil
 }
+
+// isAlreadyTerminalStatus returns true if status is one the API may respond with
+// when a prediction can't be canceled because it has already finished.
+func isAlreadyTerminalStatus(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+package replicate
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ProgressParser finds the most recent progress in a prediction's logs.
+// It returns nil if the logs don't report any progress.
+type ProgressParser func(logs string) *PredictionProgress
+
+// ProgressOption is a function that modifies progressOptions.
+type ProgressOption func(*progressOptions)
+
+// progressOptions represents options for parsing prediction progress
+type progressOptions struct {
+	parsers []ProgressParser
+}
+
+// WithProgressParser sets the parsers used to find progress in a prediction's logs,
+// replacing the default tqdm parser.
+// The parsers are tried in order, and the first progress found is returned.
+func WithProgressParser(parsers ...ProgressParser) ProgressOption {
+	return func(o *progressOptions) {
+		o.parsers = parsers
+	}
+}
+
+var (
+	tqdmProgressPattern = regexp.MustCompile(`^(?:[^|]*?:\s*)?(?P<percentage>\d+)%\s*\|.+?\|\s*(?P<current>\d+)\/(?P<total>\d+)`)
+	stepProgressPattern = regexp.MustCompile(`(?i)\bstep\s+(?P<current>\d+)\s*\/\s*(?P<total>\d+)`)
+)
+
+// ParseTqdmProgress parses progress bars printed by tqdm,
+// such as "40%|████▍     | 2/5 [00:01<00:01, 22.46it/s]",
+// optionally with a description, such as "train:  40%|████▍     | 2/5".
+func ParseTqdmProgress(logs string) *PredictionProgress {
+	return parseLastProgressLine(logs, func(line string) *PredictionProgress {
+		matches := tqdmProgressPattern.FindStringSubmatch(line)
+		if len(matches) != 4 {
+			return nil
+		}
+
+		percentage, _ := strconv.Atoi(matches[1])
+		current, _ := strconv.Atoi(matches[2])
+		total, _ := strconv.Atoi(matches[3])
+		return &PredictionProgress{
+			Percentage: float64(percentage) / float64(100),
+			Current:    current,
+			Total:      total,
+		}
+	})
+}
+
+// ParseStepProgress parses progress reported as "Step 3/10".
+func ParseStepProgress(logs string) *PredictionProgress {
+	return parseLastProgressLine(logs, func(line string) *PredictionProgress {
+		matches := stepProgressPattern.FindStringSubmatch(line)
+		if len(matches) != 3 {
+			return nil
+		}
+
+		current, _ := strconv.Atoi(matches[1])
+		total, _ := strconv.Atoi(matches[2])
+		if total == 0 {
+			return nil
+		}
+		return &PredictionProgress{
+			Percentage: float64(current) / float64(total),
+			Current:    current,
+			Total:      total,
+		}
+	})
+}
+
+// parseLastProgressLine returns the progress parsed from the last line of the logs that has any.
+//
+// Lines are scanned from the end without splitting the logs,
+// since progress is usually on the last line
+// and Progress may be called in a tight polling loop on long logs.
+func parseLastProgressLine(logs string, parse func(line string) *PredictionProgress) *PredictionProgress {
+	for end := len(logs); end >= 0; {
+		start := strings.LastIndexByte(logs[:end], '\n') + 1
+		if progress := parse(strings.TrimSpace(logs[start:end])); progress != nil {
+			return progress
+		}
+		end = start - 1
+	}
+
+	return nil
+}
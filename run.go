@@ -1,15 +1,23 @@
 package replicate
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	ErrRangeNotSupported = errors.New("server does not support range requests")
 )
 
 // RunOption is a function that modifies RunOptions
@@ -19,14 +27,67 @@ type RunOption func(*runOptions)
 type runOptions struct {
 	useFileOutput  bool
 	blockUntilDone bool
+	blockTimeout   int
+	async          bool
+
+	batchConcurrency int
+
+	inputFiles []string
 }
 
+// maxBlockTimeout is the maximum number of seconds the API holds a connection open for a prediction.
+const maxBlockTimeout = 60
+
+// defaultBatchConcurrency is the default number of predictions RunBatch runs at once.
+const defaultBatchConcurrency = 4
+
 // FileOutput is a custom type that implements io.ReadCloser and includes a URL field
 type FileOutput struct {
 	io.ReadCloser
 	URL string
 }
 
+// Save writes the contents of the file output to the given path,
+// creating any missing parent directories, and returns the number of bytes written.
+// The underlying reader is closed when Save returns.
+func (f *FileOutput) Save(ctx context.Context, path string) (int64, error) {
+	defer f.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	n, err := io.Copy(file, &contextReader{ctx: ctx, r: f.ReadCloser})
+	if err != nil {
+		return n, fmt.Errorf("failed to save file output from %s: %w", f.URL, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return n, fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return n, nil
+}
+
+// contextReader is an io.Reader that stops reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
 // WithFileOutput configures the run to automatically convert URLs in output to FileOutput objects
 func WithFileOutput() RunOption {
 	return func(o *runOptions) {
@@ -38,10 +99,58 @@ func WithFileOutput() RunOption {
 func WithBlockUntilDone() RunOption {
 	return func(o *runOptions) {
 		o.blockUntilDone = true
+		o.async = false
+	}
+}
+
+// WithAsync configures the run to return as soon as the prediction is created,
+// without waiting for it to finish.
+// The output is the created *Prediction, which can be passed to Wait.
+// It overrides an earlier WithBlockUntilDone, and WithFileOutput has no effect.
+func WithAsync() RunOption {
+	return func(o *runOptions) {
+		o.async = true
+		o.blockUntilDone = false
+		o.blockTimeout = 0
+	}
+}
+
+// WithBlockUntilDoneTimeout configures the run to block until the prediction is done,
+// holding the connection open for at most the given number of seconds.
+// The timeout is clamped between 1 and 60 seconds.
+// If the prediction hasn't finished by then, the run falls back to polling.
+func WithBlockUntilDoneTimeout(seconds int) RunOption {
+	return func(o *runOptions) {
+		o.blockUntilDone = true
+		o.blockTimeout = min(max(seconds, 1), maxBlockTimeout)
+		o.async = false
+	}
+}
+
+// WithBatchConcurrency sets the maximum number of predictions RunBatch runs at once.
+// Values less than 1 are treated as 1.
+func WithBatchConcurrency(concurrency int) RunOption {
+	return func(o *runOptions) {
+		o.batchConcurrency = max(concurrency, 1)
+	}
+}
+
+// WithInputFiles configures the run to upload the inputs with the given keys
+// as files, treating their values as local file paths.
+// The uploaded file's URL is sent in place of the path.
+//
+// Inputs that are FileRef, *os.File, or io.Reader values are always uploaded,
+// so they don't need to be listed.
+func WithInputFiles(keys ...string) RunOption {
+	return func(o *runOptions) {
+		o.inputFiles = append(o.inputFiles, keys...)
 	}
 }
 
 // RunWithOptions runs a model with specified options
+//
+// By default, it waits for the prediction to finish and returns its output.
+// With WithAsync, it returns the created *Prediction without waiting.
 func (r *Client) RunWithOptions(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook, opts ...RunOption) (PredictionOutput, error) {
 	// Initialize options
 	options := runOptions{}
@@ -55,36 +164,56 @@ func (r *Client) RunWithOptions(ctx context.Context, identifier string, input Pr
 		return nil, err
 	}
 
-	// Prepare the data for the prediction request
-	data := map[string]interface{}{}
-	path := "/predictions"
+	// Upload the inputs given as local file paths,
+	// replacing them in a copy so the caller's input is left as it was
+	if len(options.inputFiles) > 0 {
+		input = maps.Clone(input)
+	}
+	for _, key := range options.inputFiles {
+		path, ok := input[key].(string)
+		if !ok {
+			continue
+		}
+
+		file, err := r.CreateFileFromPath(ctx, path, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload input %q: %w", key, err)
+		}
+		input[key] = file
+	}
 
-	// Set the model path or version in the data
+	// Set the model or version of the prediction
+	createOptions := &createPredictionOptions{
+		input:       input,
+		webhook:     webhook,
+		wait:        options.blockUntilDone,
+		waitTimeout: options.blockTimeout,
+	}
 	if id.Version == nil {
-		path = fmt.Sprintf("/models/%s/%s/predictions", id.Owner, id.Name)
+		createOptions.modelOwner = id.Owner
+		createOptions.modelName = id.Name
 	} else {
-		data["version"] = *id.Version
+		createOptions.version = *id.Version
 	}
 
-	// Create the prediction request
-	req, err := r.createPredictionRequest(ctx, path, data, input, webhook, false)
+	// Create the prediction request, with the Prefer header set if blockUntilDone is true
+	req, err := r.createPredictionRequest(ctx, createOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	// Set the Prefer header if blockUntilDone is true
-	if options.blockUntilDone {
-		req.Header.Set("Prefer", "wait")
-	}
-
 	// Execute the request and obtain the prediction
 	prediction := &Prediction{}
 	if err := r.do(req, prediction); err != nil {
 		return nil, err
 	}
 
+	if options.async {
+		return prediction, nil
+	}
+
 	// Check if the prediction is done based on blocking preference and status
-	isDone := options.blockUntilDone && prediction.Status != Starting
+	isDone := options.blockUntilDone && prediction.Status.Terminated()
 	if !isDone {
 		// Wait for the prediction to complete
 		err = r.Wait(ctx, prediction)
@@ -111,6 +240,39 @@ func (r *Client) Run(ctx context.Context, identifier string, input PredictionInp
 	return r.RunWithOptions(ctx, identifier, input, webhook)
 }
 
+// RunBatch runs a model once for each input, with bounded concurrency.
+//
+// It returns the outputs and errors in the same order as the inputs.
+// A failed prediction doesn't stop the others from running.
+func (r *Client) RunBatch(ctx context.Context, identifier string, inputs []PredictionInput, opts ...RunOption) ([]PredictionOutput, []error) {
+	options := runOptions{
+		batchConcurrency: defaultBatchConcurrency,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	outputs := make([]PredictionOutput, len(inputs))
+	errs := make([]error, len(inputs))
+
+	g := &errgroup.Group{}
+	g.SetLimit(options.batchConcurrency)
+	for i, input := range inputs {
+		i, input := i, input
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return nil
+			}
+			outputs[i], errs[i] = r.RunWithOptions(ctx, identifier, input, nil, opts...)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return outputs, errs
+}
+
 func transformOutput(ctx context.Context, value interface{}, client *Client) (interface{}, error) {
 	var err error
 	switch v := value.(type) {
@@ -156,11 +318,10 @@ func readDataURI(uri string) (*FileOutput, error) {
 	}
 	var reader io.Reader
 	if strings.HasSuffix(mediatype, ";base64") {
-		decoded, err := base64.StdEncoding.DecodeString(data)
-		if err != nil {
-			return nil, err
-		}
-		reader = bytes.NewReader(decoded)
+		// Decode as the output is read, rather than holding
+		// a decoded copy of a possibly large payload in memory.
+		// Invalid base64 is reported by Read.
+		reader = base64.NewDecoder(base64.StdEncoding, strings.NewReader(data))
 	} else {
 		reader = strings.NewReader(data)
 	}
@@ -171,10 +332,41 @@ func readDataURI(uri string) (*FileOutput, error) {
 }
 
 func readHTTP(ctx context.Context, url string, client *Client) (*FileOutput, error) {
+	return readHTTPRange(ctx, url, 0, -1, client)
+}
+
+// ReadFileOutputRange opens the file output at the given URL,
+// reading the bytes from start to end, inclusive.
+// If end is negative, it reads from start to the end of the file.
+//
+// It can be used to resume an interrupted download of a large output,
+// by passing the number of bytes already read as start.
+// If the server doesn't support range requests, it returns ErrRangeNotSupported.
+func (r *Client) ReadFileOutputRange(ctx context.Context, url string, start, end int64) (*FileOutput, error) {
+	if start < 0 || (end >= 0 && end < start) {
+		return nil, fmt.Errorf("invalid range: start=%d, end=%d", start, end)
+	}
+
+	return readHTTPRange(ctx, url, start, end, r)
+}
+
+func readHTTPRange(ctx context.Context, url string, start, end int64, client *Client) (*FileOutput, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
+
+	// Only send a Range header when part of the file is requested,
+	// so servers that don't support ranges can still serve whole files
+	partial := start > 0 || end >= 0
+	if partial {
+		if end >= 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+		} else {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+		}
+	}
+
 	resp, err := client.c.Do(req)
 	if err != nil {
 		return nil, err
@@ -182,7 +374,11 @@ func readHTTP(ctx context.Context, url string, client *Client) (*FileOutput, err
 	if resp == nil || resp.Body == nil {
 		return nil, errors.New("HTTP request failed to get a response")
 	}
-	if resp.StatusCode != http.StatusOK {
+	if partial && resp.StatusCode == http.StatusOK {
+		resp.Body.Close()
+		return nil, ErrRangeNotSupported
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
 		resp.Body.Close()
 		return nil, fmt.Errorf("HTTP request failed with status code %d", resp.StatusCode)
 	}
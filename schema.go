@@ -0,0 +1,330 @@
+package replicate
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+
+	"github.com/replicate/replicate-go/schema"
+)
+
+var (
+	ErrNoSchema     = errors.New("model version has no OpenAPI schema")
+	ErrInvalidInput = errors.New("invalid input")
+)
+
+// OpenAPISchema describes the input or output of a model version,
+// as generated by Cog.
+type OpenAPISchema struct {
+	Title      string                    `json:"title,omitempty"`
+	Type       string                    `json:"type,omitempty"`
+	Properties map[string]SchemaProperty `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+	Items      *SchemaProperty           `json:"items,omitempty"`
+	Format     string                    `json:"format,omitempty"`
+}
+
+// SchemaProperty describes a single property of an OpenAPISchema.
+type SchemaProperty struct {
+	Title       string          `json:"title,omitempty"`
+	Type        string          `json:"type,omitempty"`
+	Format      string          `json:"format,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Default     interface{}     `json:"default,omitempty"`
+	Enum        []interface{}   `json:"enum,omitempty"`
+	Minimum     *float64        `json:"minimum,omitempty"`
+	Maximum     *float64        `json:"maximum,omitempty"`
+	Order       int             `json:"x-order,omitempty"`
+	Items       *SchemaProperty `json:"items,omitempty"`
+
+	// Properties and Required describe the properties of an object property.
+	Properties map[string]SchemaProperty `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+}
+
+// PropertyNames returns the names of the schema's properties,
+// in the order defined by the model.
+func (s *OpenAPISchema) PropertyNames() []string {
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := s.Properties[names[i]], s.Properties[names[j]]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// IsRequired returns true if the named property is required.
+func (s *OpenAPISchema) IsRequired(name string) bool {
+	for _, required := range s.Required {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// InputSchema parses the input schema of the model version.
+func (m *ModelVersion) InputSchema() (*OpenAPISchema, error) {
+	parsed, err := m.parseSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &OpenAPISchema{
+		Title: "Input",
+		Type:  "object",
+	}
+	s.Properties, s.Required = newSchemaProperties(parsed.Input)
+	return s, nil
+}
+
+// OutputSchema parses the output schema of the model version.
+func (m *ModelVersion) OutputSchema() (*OpenAPISchema, error) {
+	parsed, err := m.parseSchema()
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Output == nil {
+		return nil, errors.New("OpenAPI schema has no Output component")
+	}
+
+	output := newSchemaProperty(*parsed.Output, 0)
+	return &OpenAPISchema{
+		Title:      output.Title,
+		Type:       output.Type,
+		Properties: output.Properties,
+		Required:   output.Required,
+		Items:      output.Items,
+		Format:     output.Format,
+	}, nil
+}
+
+// ValidateInput checks the input against the input schema of the model version.
+//
+// It reports missing required properties, unknown properties,
+// values of the wrong type, and values not in the list of allowed choices,
+// including the properties of nested objects and the items of arrays.
+func (m *ModelVersion) ValidateInput(input PredictionInput) error {
+	schema, err := m.InputSchema()
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(validateProperties("", schema.Properties, schema.Required, input)...)
+}
+
+// validateProperties checks the values of an object's properties.
+// path is the name of the object, or empty for the input itself.
+func validateProperties(path string, properties map[string]SchemaProperty, required []string, values map[string]interface{}) []error {
+	name := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	var errs []error
+	for _, key := range required {
+		if _, ok := values[key]; !ok {
+			errs = append(errs, fmt.Errorf("%w: missing required property %q", ErrInvalidInput, name(key)))
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		property, ok := properties[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: unknown property %q", ErrInvalidInput, name(key)))
+			continue
+		}
+		errs = append(errs, property.validate(name(key), values[key])...)
+	}
+
+	return errs
+}
+
+// parseSchema parses the model version's OpenAPI schema with schema.Parse,
+// which resolves references to other schemas.
+func (m *ModelVersion) parseSchema() (*schema.Schema, error) {
+	if m.OpenAPISchema == nil {
+		return nil, ErrNoSchema
+	}
+
+	parsed, err := schema.Parse(m.OpenAPISchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI schema: %w", err)
+	}
+	return parsed, nil
+}
+
+// newSchemaProperty converts a field parsed by the schema package to a SchemaProperty.
+// Fields are already in the order defined by the model, so order is their index.
+func newSchemaProperty(field schema.Field, order int) SchemaProperty {
+	p := SchemaProperty{
+		Title:       field.Title,
+		Type:        field.Type,
+		Format:      field.Format,
+		Description: field.Description,
+		Default:     field.Default,
+		Enum:        field.Enum,
+		Minimum:     field.Minimum,
+		Maximum:     field.Maximum,
+		Order:       order,
+	}
+	if field.Items != nil {
+		items := newSchemaProperty(*field.Items, 0)
+		p.Items = &items
+	}
+	p.Properties, p.Required = newSchemaProperties(field.Properties)
+	return p
+}
+
+func newSchemaProperties(fields []schema.Field) (map[string]SchemaProperty, []string) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	properties := make(map[string]SchemaProperty, len(fields))
+	var required []string
+	for i, field := range fields {
+		properties[field.Name] = newSchemaProperty(field, i)
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+	return properties, required
+}
+
+// validate checks a value against the property,
+// including the items of an array and the properties of an object.
+func (p SchemaProperty) validate(path string, value interface{}) []error {
+	if err := p.validateValue(value); err != nil {
+		return []error{fmt.Errorf("%w: property %q %s", ErrInvalidInput, path, err)}
+	}
+
+	var errs []error
+	if p.Items != nil {
+		v := reflect.ValueOf(value)
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				errs = append(errs, p.Items.validate(fmt.Sprintf("%s[%d]", path, i), v.Index(i).Interface())...)
+			}
+		}
+	}
+	if len(p.Properties) > 0 {
+		if values, ok := toMap(value); ok {
+			errs = append(errs, validateProperties(path, p.Properties, p.Required, values)...)
+		}
+	}
+	return errs
+}
+
+// validateValue checks the type, choices, and range of a value.
+func (p SchemaProperty) validateValue(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+
+	if !matchesType(p.Type, value) {
+		return fmt.Errorf("must be of type %s, got %T", p.Type, value)
+	}
+
+	if len(p.Enum) > 0 {
+		found := false
+		for _, choice := range p.Enum {
+			if reflect.DeepEqual(normalizeValue(choice), normalizeValue(value)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("must be one of %v, got %v", p.Enum, value)
+		}
+	}
+
+	if n, ok := toFloat(value); ok {
+		if p.Minimum != nil && n < *p.Minimum {
+			return fmt.Errorf("must be greater than or equal to %v, got %v", *p.Minimum, value)
+		}
+		if p.Maximum != nil && n > *p.Maximum {
+			return fmt.Errorf("must be less than or equal to %v, got %v", *p.Maximum, value)
+		}
+	}
+
+	return nil
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "string":
+		switch value.(type) {
+		case string, *File, FileRef, *FileRef, io.Reader:
+			return true
+		}
+		return false
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		kind := reflect.ValueOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	case "object":
+		return reflect.ValueOf(value).Kind() == reflect.Map
+	default:
+		return true
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func toMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case PredictionInput:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+// normalizeValue converts numbers to float64 so that values decoded from JSON
+// can be compared with values provided by the caller.
+func normalizeValue(value interface{}) interface{} {
+	if n, ok := toFloat(value); ok {
+		return n
+	}
+	return value
+}
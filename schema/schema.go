@@ -0,0 +1,334 @@
+// Package schema parses the OpenAPI schemas that Cog generates for models
+// into a list of fields, with the metadata needed to build a form for any model.
+//
+// The OpenAPI schema of a model version is available as replicate.ModelVersion.OpenAPISchema.
+// ModelVersion.InputSchema, OutputSchema, and ValidateInput are built on Parse.
+package schema // import "github.com/replicate/replicate-go/schema"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrInvalidSchema = errors.New("invalid OpenAPI schema")
+)
+
+// refPrefix is the prefix of references to the schemas in the document's components.
+const refPrefix = "#/components/schemas/"
+
+// Schema describes the input and output of a model version.
+type Schema struct {
+	// Input lists the model's input fields, in the order defined by the model.
+	Input []Field
+
+	// Output describes the model's output. Its Name is empty.
+	// It's nil if the schema doesn't describe the output.
+	Output *Field
+}
+
+// Field describes an input field, an output, or a property of an object.
+type Field struct {
+	Name        string
+	Title       string
+	Description string
+
+	// Type is the JSON type of the field, such as "string", "integer", or "object".
+	// It's empty if the field can have any type.
+	Type string
+
+	// Format refines the type, such as "uri" for files and URLs.
+	Format string
+
+	Required bool
+	Default  interface{}
+	Enum     []interface{}
+	Minimum  *float64
+	Maximum  *float64
+
+	// Items describes the items of an array field.
+	Items *Field
+
+	// Properties describes the properties of an object field, in the order defined by the model.
+	Properties []Field
+}
+
+// Parse parses an OpenAPI document generated by Cog.
+// The document may be the decoded JSON, like ModelVersion.OpenAPISchema,
+// or the JSON itself as a []byte, json.RawMessage, or string.
+//
+// References to other schemas, such as the enums Cog generates for choices,
+// are resolved and merged into the fields that use them.
+func Parse(openapi interface{}) (*Schema, error) {
+	var data []byte
+	switch v := openapi.(type) {
+	case nil:
+		return nil, fmt.Errorf("%w: no document", ErrInvalidSchema)
+	case []byte:
+		data = v
+	case json.RawMessage:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal OpenAPI schema: %w", err)
+		}
+	}
+
+	document := &document{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSchema, err)
+	}
+
+	p := &parser{
+		schemas:   document.Components.Schemas,
+		resolving: map[string]bool{},
+	}
+
+	input, ok := p.schemas["Input"]
+	if !ok {
+		return nil, fmt.Errorf("%w: no Input component", ErrInvalidSchema)
+	}
+	inputField, err := p.field("", input, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Input: %w", err)
+	}
+
+	schema := &Schema{
+		Input: inputField.Properties,
+	}
+
+	if output, ok := p.schemas["Output"]; ok {
+		outputField, err := p.field("", output, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse Output: %w", err)
+		}
+		schema.Output = &outputField
+	}
+
+	return schema, nil
+}
+
+type document struct {
+	Components struct {
+		Schemas map[string]node `json:"schemas"`
+	} `json:"components"`
+}
+
+// node is a schema object in an OpenAPI document.
+type node struct {
+	Ref   string `json:"$ref,omitempty"`
+	AllOf []node `json:"allOf,omitempty"`
+	AnyOf []node `json:"anyOf,omitempty"`
+
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Type        jsonType      `json:"type,omitempty"`
+	Format      string        `json:"format,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+	Order       int           `json:"x-order,omitempty"`
+
+	Items      *node           `json:"items,omitempty"`
+	Properties map[string]node `json:"properties,omitempty"`
+	Required   []string        `json:"required,omitempty"`
+}
+
+// jsonType is the type of a schema.
+// Newer versions of OpenAPI allow a list of types, like ["string", "null"],
+// so the first type other than "null" is used.
+type jsonType string
+
+func (t *jsonType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = jsonType(single)
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	for _, typ := range multiple {
+		if typ != "null" {
+			*t = jsonType(typ)
+			break
+		}
+	}
+	return nil
+}
+
+type parser struct {
+	schemas map[string]node
+
+	// resolving holds the references being resolved by the current field
+	// and its parents, to detect recursive schemas.
+	resolving map[string]bool
+}
+
+// field converts a node to a field, resolving its references.
+func (p *parser) field(name string, n node, required bool) (Field, error) {
+	n, refs, err := p.resolve(n)
+	if err != nil {
+		return Field{}, err
+	}
+
+	// The field's items and properties can't refer back to the field
+	for _, ref := range refs {
+		p.resolving[ref] = true
+	}
+	defer func() {
+		for _, ref := range refs {
+			delete(p.resolving, ref)
+		}
+	}()
+
+	field := Field{
+		Name:        name,
+		Title:       n.Title,
+		Description: n.Description,
+		Type:        string(n.Type),
+		Format:      n.Format,
+		Required:    required,
+		Default:     n.Default,
+		Enum:        n.Enum,
+		Minimum:     n.Minimum,
+		Maximum:     n.Maximum,
+	}
+
+	if n.Items != nil {
+		items, err := p.field("", *n.Items, false)
+		if err != nil {
+			return Field{}, fmt.Errorf("items: %w", err)
+		}
+		field.Items = &items
+	}
+
+	names := make([]string, 0, len(n.Properties))
+	for key := range n.Properties {
+		names = append(names, key)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		a, b := n.Properties[names[i]], n.Properties[names[j]]
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return names[i] < names[j]
+	})
+
+	for _, key := range names {
+		property, err := p.field(key, n.Properties[key], slices.Contains(n.Required, key))
+		if err != nil {
+			return Field{}, fmt.Errorf("property %q: %w", key, err)
+		}
+		field.Properties = append(field.Properties, property)
+	}
+
+	return field, nil
+}
+
+// resolve merges the schemas a node refers to into it,
+// and returns the names of the schemas it referred to.
+// Values set on the node itself take precedence over referenced ones,
+// so a property can override the title or default of the enum it refers to.
+func (p *parser) resolve(n node) (node, []string, error) {
+	var refs []string
+
+	if n.Ref != "" {
+		name, ok := strings.CutPrefix(n.Ref, refPrefix)
+		if !ok {
+			return node{}, nil, fmt.Errorf("%w: unsupported reference %q", ErrInvalidSchema, n.Ref)
+		}
+		referenced, ok := p.schemas[name]
+		if !ok {
+			return node{}, nil, fmt.Errorf("%w: unknown reference %q", ErrInvalidSchema, n.Ref)
+		}
+		if p.resolving[name] {
+			return node{}, nil, fmt.Errorf("%w: recursive reference %q", ErrInvalidSchema, n.Ref)
+		}
+
+		p.resolving[name] = true
+		referenced, subRefs, err := p.resolve(referenced)
+		delete(p.resolving, name)
+		if err != nil {
+			return node{}, nil, err
+		}
+
+		n.Ref = ""
+		n = merge(n, referenced)
+		refs = append(append(refs, name), subRefs...)
+	}
+
+	for _, sub := range n.AllOf {
+		sub, subRefs, err := p.resolve(sub)
+		if err != nil {
+			return node{}, nil, err
+		}
+		n = merge(n, sub)
+		refs = append(refs, subRefs...)
+	}
+	n.AllOf = nil
+
+	// Optional fields are an anyOf of the type and null
+	for _, sub := range n.AnyOf {
+		if sub.Type == "null" {
+			continue
+		}
+		sub, subRefs, err := p.resolve(sub)
+		if err != nil {
+			return node{}, nil, err
+		}
+		n = merge(n, sub)
+		refs = append(refs, subRefs...)
+		break
+	}
+	n.AnyOf = nil
+
+	return n, refs, nil
+}
+
+// merge fills the values that aren't set on n from other.
+func merge(n node, other node) node {
+	if n.Title == "" {
+		n.Title = other.Title
+	}
+	if n.Description == "" {
+		n.Description = other.Description
+	}
+	if n.Type == "" {
+		n.Type = other.Type
+	}
+	if n.Format == "" {
+		n.Format = other.Format
+	}
+	if n.Default == nil {
+		n.Default = other.Default
+	}
+	if n.Enum == nil {
+		n.Enum = other.Enum
+	}
+	if n.Minimum == nil {
+		n.Minimum = other.Minimum
+	}
+	if n.Maximum == nil {
+		n.Maximum = other.Maximum
+	}
+	if n.Items == nil {
+		n.Items = other.Items
+	}
+	if n.Properties == nil {
+		n.Properties = other.Properties
+		n.Required = other.Required
+	}
+	return n
+}
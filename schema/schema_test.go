@@ -0,0 +1,201 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go/schema"
+)
+
+// imageModelSchema is the schema of an image generation model,
+// with choices represented as references to enums.
+const imageModelSchema = `{
+  "openapi": "3.0.2",
+  "info": {"title": "Cog", "version": "0.1.0"},
+  "components": {
+    "schemas": {
+      "Input": {
+        "type": "object",
+        "title": "Input",
+        "required": ["prompt"],
+        "properties": {
+          "seed": {"type": "integer", "title": "Seed", "x-order": 5, "description": "Random seed. Leave blank to randomize the seed"},
+          "width": {"type": "integer", "title": "Width", "x-order": 2, "default": 1024, "minimum": 256, "maximum": 2048},
+          "prompt": {"type": "string", "title": "Prompt", "x-order": 0, "description": "Input prompt"},
+          "scheduler": {"allOf": [{"$ref": "#/components/schemas/scheduler"}], "x-order": 3, "default": "K_EULER", "description": "scheduler"},
+          "image": {"type": "string", "title": "Image", "format": "uri", "x-order": 1, "description": "Input image for img2img mode"},
+          "guidance_scale": {"type": "number", "title": "Guidance Scale", "x-order": 4, "default": 7.5, "minimum": 1, "maximum": 50}
+        }
+      },
+      "Output": {
+        "type": "array",
+        "title": "Output",
+        "items": {"type": "string", "format": "uri"}
+      },
+      "scheduler": {
+        "enum": ["DDIM", "DPMSolverMultistep", "K_EULER"],
+        "type": "string",
+        "title": "scheduler",
+        "description": "An enumeration."
+      }
+    }
+  }
+}`
+
+// transcriptionModelSchema is the schema of a speech transcription model,
+// with an output object that nests other objects.
+const transcriptionModelSchema = `{
+  "openapi": "3.1.0",
+  "info": {"title": "Cog", "version": "0.1.0"},
+  "components": {
+    "schemas": {
+      "Input": {
+        "type": "object",
+        "title": "Input",
+        "required": ["audio"],
+        "properties": {
+          "audio": {"type": "string", "title": "Audio", "format": "uri", "x-order": 0},
+          "language": {"anyOf": [{"type": "string"}, {"type": "null"}], "title": "Language", "x-order": 1},
+          "temperature": {"type": ["number", "null"], "title": "Temperature", "x-order": 2, "default": 0}
+        }
+      },
+      "Output": {
+        "$ref": "#/components/schemas/ModelOutput",
+        "title": "Output"
+      },
+      "ModelOutput": {
+        "type": "object",
+        "title": "ModelOutput",
+        "required": ["segments", "transcription"],
+        "properties": {
+          "transcription": {"type": "string", "title": "Transcription"},
+          "segments": {"type": "array", "title": "Segments", "items": {"$ref": "#/components/schemas/Segment"}},
+          "detected_language": {"type": "string", "title": "Detected Language"}
+        }
+      },
+      "Segment": {
+        "type": "object",
+        "title": "Segment",
+        "required": ["start", "end", "text"],
+        "properties": {
+          "start": {"type": "number", "title": "Start", "x-order": 0},
+          "end": {"type": "number", "title": "End", "x-order": 1},
+          "text": {"type": "string", "title": "Text", "x-order": 2}
+        }
+      }
+    }
+  }
+}`
+
+func TestParseImageModel(t *testing.T) {
+	var document interface{}
+	require.NoError(t, json.Unmarshal([]byte(imageModelSchema), &document))
+
+	s, err := schema.Parse(document)
+	require.NoError(t, err)
+
+	names := make([]string, len(s.Input))
+	for i, field := range s.Input {
+		names[i] = field.Name
+	}
+	assert.Equal(t, []string{"prompt", "image", "width", "scheduler", "guidance_scale", "seed"}, names)
+
+	prompt := s.Input[0]
+	assert.Equal(t, "Prompt", prompt.Title)
+	assert.Equal(t, "string", prompt.Type)
+	assert.Equal(t, "Input prompt", prompt.Description)
+	assert.True(t, prompt.Required)
+
+	image := s.Input[1]
+	assert.Equal(t, "uri", image.Format)
+	assert.False(t, image.Required)
+
+	width := s.Input[2]
+	assert.Equal(t, "integer", width.Type)
+	assert.Equal(t, 1024.0, width.Default)
+	require.NotNil(t, width.Minimum)
+	require.NotNil(t, width.Maximum)
+	assert.Equal(t, 256.0, *width.Minimum)
+	assert.Equal(t, 2048.0, *width.Maximum)
+
+	// The enum is resolved, and the property's own values take precedence
+	scheduler := s.Input[3]
+	assert.Equal(t, "string", scheduler.Type)
+	assert.Equal(t, []interface{}{"DDIM", "DPMSolverMultistep", "K_EULER"}, scheduler.Enum)
+	assert.Equal(t, "K_EULER", scheduler.Default)
+	assert.Equal(t, "scheduler", scheduler.Title)
+	assert.Equal(t, "scheduler", scheduler.Description)
+
+	require.NotNil(t, s.Output)
+	assert.Equal(t, "array", s.Output.Type)
+	require.NotNil(t, s.Output.Items)
+	assert.Equal(t, "string", s.Output.Items.Type)
+	assert.Equal(t, "uri", s.Output.Items.Format)
+}
+
+func TestParseTranscriptionModel(t *testing.T) {
+	s, err := schema.Parse([]byte(transcriptionModelSchema))
+	require.NoError(t, err)
+
+	require.Len(t, s.Input, 3)
+	assert.Equal(t, "audio", s.Input[0].Name)
+	assert.True(t, s.Input[0].Required)
+
+	// Optional types are unwrapped
+	assert.Equal(t, "language", s.Input[1].Name)
+	assert.Equal(t, "string", s.Input[1].Type)
+	assert.Equal(t, "temperature", s.Input[2].Name)
+	assert.Equal(t, "number", s.Input[2].Type)
+
+	output := s.Output
+	require.NotNil(t, output)
+	assert.Equal(t, "object", output.Type)
+	assert.Equal(t, "Output", output.Title)
+	require.Len(t, output.Properties, 3)
+
+	// Properties without an order are sorted by name
+	assert.Equal(t, "detected_language", output.Properties[0].Name)
+	assert.False(t, output.Properties[0].Required)
+	assert.Equal(t, "segments", output.Properties[1].Name)
+	assert.True(t, output.Properties[1].Required)
+
+	segments := output.Properties[1]
+	assert.Equal(t, "array", segments.Type)
+	require.NotNil(t, segments.Items)
+	assert.Equal(t, "object", segments.Items.Type)
+	require.Len(t, segments.Items.Properties, 3)
+	for i, name := range []string{"start", "end", "text"} {
+		assert.Equal(t, name, segments.Items.Properties[i].Name)
+		assert.True(t, segments.Items.Properties[i].Required)
+	}
+}
+
+func TestParseInvalidSchema(t *testing.T) {
+	testCases := []struct {
+		name     string
+		document interface{}
+	}{
+		{name: "nil", document: nil},
+		{name: "not JSON", document: "not JSON"},
+		{name: "no Input", document: `{"components": {"schemas": {"Output": {"type": "string"}}}}`},
+		{name: "unknown reference", document: `{"components": {"schemas": {"Input": {"$ref": "#/components/schemas/Missing"}}}}`},
+		{name: "external reference", document: `{"components": {"schemas": {"Input": {"$ref": "other.json#/Input"}}}}`},
+		{
+			name: "recursive reference",
+			document: `{"components": {"schemas": {
+				"Input": {"type": "object", "properties": {"tree": {"$ref": "#/components/schemas/Node"}}},
+				"Node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}}}
+			}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := schema.Parse(tc.document)
+			assert.ErrorIs(t, err, schema.ErrInvalidSchema)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package replicate_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+	"github.com/replicate/replicate-go/schema"
+)
+
+const testOpenAPISchema = `{
+  "openapi": "3.0.2",
+  "info": {"title": "Cog", "version": "0.1.0"},
+  "components": {
+    "schemas": {
+      "Input": {
+        "type": "object",
+        "title": "Input",
+        "required": ["prompt"],
+        "properties": {
+          "prompt": {"type": "string", "title": "Prompt", "x-order": 0, "description": "Input prompt"},
+          "num_outputs": {"type": "integer", "title": "Num Outputs", "x-order": 2, "default": 1, "minimum": 1, "maximum": 4},
+          "guidance_scale": {"type": "number", "title": "Guidance Scale", "x-order": 3, "default": 7.5},
+          "scheduler": {"allOf": [{"$ref": "#/components/schemas/scheduler"}], "x-order": 1, "default": "DDIM"},
+          "image": {"type": "string", "format": "uri", "title": "Image", "x-order": 4}
+        }
+      },
+      "Output": {
+        "type": "array",
+        "title": "Output",
+        "items": {"type": "string", "format": "uri"}
+      },
+      "scheduler": {
+        "enum": ["DDIM", "K_EULER"],
+        "type": "string",
+        "title": "scheduler",
+        "description": "An enumeration."
+      }
+    }
+  }
+}`
+
+func testModelVersion(t *testing.T) *replicate.ModelVersion {
+	var openapi interface{}
+	require.NoError(t, json.Unmarshal([]byte(testOpenAPISchema), &openapi))
+	return &replicate.ModelVersion{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", OpenAPISchema: openapi}
+}
+
+func TestModelVersionInputSchema(t *testing.T) {
+	version := testModelVersion(t)
+
+	schema, err := version.InputSchema()
+	require.NoError(t, err)
+
+	assert.Equal(t, "object", schema.Type)
+	assert.Equal(t, []string{"prompt", "scheduler", "num_outputs", "guidance_scale", "image"}, schema.PropertyNames())
+	assert.True(t, schema.IsRequired("prompt"))
+	assert.False(t, schema.IsRequired("scheduler"))
+
+	assert.Equal(t, "Input prompt", schema.Properties["prompt"].Description)
+	assert.Equal(t, 1.0, schema.Properties["num_outputs"].Default)
+	assert.Equal(t, 4.0, *schema.Properties["num_outputs"].Maximum)
+	assert.Equal(t, "uri", schema.Properties["image"].Format)
+
+	// Referenced enums are resolved
+	scheduler := schema.Properties["scheduler"]
+	assert.Equal(t, "string", scheduler.Type)
+	assert.Equal(t, []interface{}{"DDIM", "K_EULER"}, scheduler.Enum)
+	assert.Equal(t, "DDIM", scheduler.Default)
+}
+
+func TestModelVersionOutputSchema(t *testing.T) {
+	version := testModelVersion(t)
+
+	schema, err := version.OutputSchema()
+	require.NoError(t, err)
+
+	assert.Equal(t, "array", schema.Type)
+	require.NotNil(t, schema.Items)
+	assert.Equal(t, "string", schema.Items.Type)
+	assert.Equal(t, "uri", schema.Items.Format)
+}
+
+func TestModelVersionValidateInput(t *testing.T) {
+	version := testModelVersion(t)
+
+	testCases := []struct {
+		name    string
+		input   replicate.PredictionInput
+		wantErr string
+	}{
+		{
+			name:  "Valid",
+			input: replicate.PredictionInput{"prompt": "An astronaut", "num_outputs": 2, "scheduler": "K_EULER", "guidance_scale": 5},
+		},
+		{
+			name:  "File input",
+			input: replicate.PredictionInput{"prompt": "An astronaut", "image": &replicate.File{ID: "file-id"}},
+		},
+		{
+			name:    "Missing required property",
+			input:   replicate.PredictionInput{"num_outputs": 2},
+			wantErr: `missing required property "prompt"`,
+		},
+		{
+			name:    "Unknown property",
+			input:   replicate.PredictionInput{"prompt": "An astronaut", "negative": "blurry"},
+			wantErr: `unknown property "negative"`,
+		},
+		{
+			name:    "Wrong type",
+			input:   replicate.PredictionInput{"prompt": "An astronaut", "num_outputs": 1.5},
+			wantErr: `property "num_outputs" must be of type integer`,
+		},
+		{
+			name:    "Out of range",
+			input:   replicate.PredictionInput{"prompt": "An astronaut", "num_outputs": 5},
+			wantErr: `property "num_outputs" must be less than or equal to 4`,
+		},
+		{
+			name:    "Invalid choice",
+			input:   replicate.PredictionInput{"prompt": "An astronaut", "scheduler": "PNDM"},
+			wantErr: `property "scheduler" must be one of [DDIM K_EULER]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := version.ValidateInput(tc.input)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, replicate.ErrInvalidInput)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+const testNestedOpenAPISchema = `{
+  "openapi": "3.0.2",
+  "components": {
+    "schemas": {
+      "Input": {
+        "type": "object",
+        "properties": {
+          "scheduler": {"$ref": "#/components/schemas/scheduler"},
+          "seed": {"anyOf": [{"type": "integer"}, {"type": "null"}], "title": "Seed"},
+          "region": {"$ref": "#/components/schemas/region"},
+          "tags": {"type": "array", "items": {"$ref": "#/components/schemas/scheduler"}}
+        }
+      },
+      "scheduler": {"enum": ["DDIM", "K_EULER"], "type": "string"},
+      "region": {
+        "type": "object",
+        "required": ["width"],
+        "properties": {
+          "width": {"type": "integer", "minimum": 1},
+          "height": {"type": "integer", "minimum": 1}
+        }
+      }
+    }
+  }
+}`
+
+func TestModelVersionValidateInputReferences(t *testing.T) {
+	var openapi interface{}
+	require.NoError(t, json.Unmarshal([]byte(testNestedOpenAPISchema), &openapi))
+	version := &replicate.ModelVersion{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", OpenAPISchema: openapi}
+
+	testCases := []struct {
+		name    string
+		input   replicate.PredictionInput
+		wantErr string
+	}{
+		{
+			name:  "Valid",
+			input: replicate.PredictionInput{"scheduler": "DDIM", "seed": 42, "region": map[string]interface{}{"width": 512, "height": 512}, "tags": []interface{}{"K_EULER"}},
+		},
+		{
+			name:  "Optional property",
+			input: replicate.PredictionInput{"seed": nil},
+		},
+		{
+			name:    "Invalid choice in reference",
+			input:   replicate.PredictionInput{"scheduler": "PNDM"},
+			wantErr: `property "scheduler" must be one of [DDIM K_EULER]`,
+		},
+		{
+			name:    "Wrong type in anyOf",
+			input:   replicate.PredictionInput{"seed": "42"},
+			wantErr: `property "seed" must be of type integer`,
+		},
+		{
+			name:    "Wrong type in nested object",
+			input:   replicate.PredictionInput{"region": map[string]interface{}{"width": "wide"}},
+			wantErr: `property "region.width" must be of type integer`,
+		},
+		{
+			name:    "Missing property in nested object",
+			input:   replicate.PredictionInput{"region": map[string]interface{}{"height": 512}},
+			wantErr: `missing required property "region.width"`,
+		},
+		{
+			name:    "Unknown property in nested object",
+			input:   replicate.PredictionInput{"region": map[string]interface{}{"width": 512, "depth": 3}},
+			wantErr: `unknown property "region.depth"`,
+		},
+		{
+			name:    "Invalid array item",
+			input:   replicate.PredictionInput{"tags": []interface{}{"DDIM", "PNDM"}},
+			wantErr: `property "tags[1]" must be one of [DDIM K_EULER]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := version.ValidateInput(tc.input)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, replicate.ErrInvalidInput)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestModelVersionValidateInputUnresolvedReference(t *testing.T) {
+	var openapi interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "components": {
+    "schemas": {
+      "Input": {
+        "type": "object",
+        "properties": {
+          "scheduler": {"allOf": [{"$ref": "#/components/schemas/missing"}]}
+        }
+      }
+    }
+  }
+}`), &openapi))
+	version := &replicate.ModelVersion{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa", OpenAPISchema: openapi}
+
+	err := version.ValidateInput(replicate.PredictionInput{"scheduler": "PNDM"})
+	assert.ErrorIs(t, err, schema.ErrInvalidSchema)
+}
+
+func TestModelVersionWithoutSchema(t *testing.T) {
+	version := &replicate.ModelVersion{ID: "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa"}
+
+	_, err := version.InputSchema()
+	assert.ErrorIs(t, err, replicate.ErrNoSchema)
+}
@@ -0,0 +1,90 @@
+package replicate
+
+import (
+	"context"
+	"io"
+)
+
+// The interfaces below each describe part of the Client's API.
+// Code that depends on them, rather than on *Client,
+// can be tested by passing a fake implementation instead.
+// A fake can embed the interface to implement only the methods it needs.
+
+// PredictionService creates and manages predictions.
+type PredictionService interface {
+	CreatePredictionWithOptions(ctx context.Context, opts ...CreatePredictionOption) (*Prediction, error)
+	CreatePrediction(ctx context.Context, version string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	CreatePredictionWithModel(ctx context.Context, modelOwner string, modelName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	CreatePredictionWithVersion(ctx context.Context, version *ModelVersion, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	GetPrediction(ctx context.Context, id string) (*Prediction, error)
+	ListPredictions(ctx context.Context) (*Page[Prediction], error)
+	CancelPrediction(ctx context.Context, id string) (*Prediction, error)
+	Wait(ctx context.Context, prediction *Prediction, opts ...WaitOption) error
+	WaitAsync(ctx context.Context, prediction *Prediction, opts ...WaitOption) (<-chan *Prediction, <-chan error)
+	WaitAsyncStream(ctx context.Context, prediction *Prediction, opts ...WaitOption) (<-chan *Prediction, <-chan error)
+}
+
+// Runner runs models and returns their output.
+type Runner interface {
+	Run(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook) (PredictionOutput, error)
+	RunWithOptions(ctx context.Context, identifier string, input PredictionInput, webhook *Webhook, opts ...RunOption) (PredictionOutput, error)
+}
+
+// ModelService creates and manages models and their versions.
+type ModelService interface {
+	GetModel(ctx context.Context, modelOwner string, modelName string) (*Model, error)
+	ListModels(ctx context.Context) (*Page[Model], error)
+	ListModelsWithOptions(ctx context.Context, opts ...ListModelsOption) (*Page[Model], error)
+	ListModelsByOwner(ctx context.Context, modelOwner string) (*Page[Model], error)
+	ListModelExamples(ctx context.Context, modelOwner string, modelName string) (*Page[Prediction], error)
+	CreateModel(ctx context.Context, modelOwner string, modelName string, options CreateModelOptions) (*Model, error)
+	DeleteModel(ctx context.Context, modelOwner string, modelName string) error
+	GetModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string) (*ModelVersion, error)
+	ListModelVersions(ctx context.Context, modelOwner string, modelName string) (*Page[ModelVersion], error)
+	ListAllModelVersions(ctx context.Context, modelOwner string, modelName string) ([]ModelVersion, error)
+	LatestVersion(ctx context.Context, modelOwner string, modelName string) (*ModelVersion, error)
+	GetModelVersionByPrefix(ctx context.Context, modelOwner string, modelName string, prefix string) (*ModelVersion, error)
+	DeleteModelVersion(ctx context.Context, modelOwner string, modelName string, versionID string, opts ...DeleteModelVersionOption) error
+}
+
+// TrainingService creates and manages trainings.
+type TrainingService interface {
+	CreateTraining(ctx context.Context, modelOwner string, modelName string, version string, destination string, input TrainingInput, webhook *Webhook) (*Training, error)
+	CreateTrainingWithOptions(ctx context.Context, opts ...CreateTrainingOption) (*Training, error)
+	GetTraining(ctx context.Context, trainingID string) (*Training, error)
+	ListTrainings(ctx context.Context) (*Page[Training], error)
+	CancelTraining(ctx context.Context, trainingID string) (*Training, error)
+	WaitForTraining(ctx context.Context, training *Training, opts ...WaitOption) error
+	StreamTrainingLogs(ctx context.Context, id string, opts ...WaitOption) (<-chan string, <-chan error)
+}
+
+// DeploymentService creates and manages deployments, and the predictions made with them.
+type DeploymentService interface {
+	CreatePredictionWithDeployment(ctx context.Context, deploymentOwner string, deploymentName string, input PredictionInput, webhook *Webhook, stream bool) (*Prediction, error)
+	GetDeployment(ctx context.Context, deploymentOwner string, deploymentName string) (*Deployment, error)
+	ListDeployments(ctx context.Context) (*Page[Deployment], error)
+	CreateDeployment(ctx context.Context, options CreateDeploymentOptions) (*Deployment, error)
+	UpdateDeployment(ctx context.Context, deploymentOwner string, deploymentName string, options UpdateDeploymentOptions) (*Deployment, error)
+	DeleteDeployment(ctx context.Context, deploymentOwner string, deploymentName string) error
+}
+
+// FileService uploads and manages files.
+type FileService interface {
+	CreateFileFromPath(ctx context.Context, filePath string, options *CreateFileOptions) (*File, error)
+	CreateFileFromBytes(ctx context.Context, data []byte, options *CreateFileOptions) (*File, error)
+	CreateFileFromReader(ctx context.Context, reader io.Reader, options *CreateFileOptions) (*File, error)
+	GetFile(ctx context.Context, fileID string) (*File, error)
+	ListFiles(ctx context.Context) (*Page[File], error)
+	DownloadFile(ctx context.Context, fileID string) (io.ReadCloser, error)
+	DownloadFileVerified(ctx context.Context, fileID string) (io.ReadCloser, error)
+	DeleteFile(ctx context.Context, fileID string) error
+}
+
+var (
+	_ PredictionService = (*Client)(nil)
+	_ Runner            = (*Client)(nil)
+	_ ModelService      = (*Client)(nil)
+	_ TrainingService   = (*Client)(nil)
+	_ DeploymentService = (*Client)(nil)
+	_ FileService       = (*Client)(nil)
+)
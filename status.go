@@ -17,3 +17,28 @@ func (s Status) String() string {
 func (s Status) Terminated() bool {
 	return s == Succeeded || s == Failed || s == Canceled
 }
+
+// Succeeded returns true if the status is Succeeded.
+func (s Status) Succeeded() bool {
+	return s == Succeeded
+}
+
+// Failed returns true if the status is Failed.
+func (s Status) Failed() bool {
+	return s == Failed
+}
+
+// Canceled returns true if the status is Canceled.
+func (s Status) Canceled() bool {
+	return s == Canceled
+}
+
+// IsValid returns true if the status is one of the known statuses.
+func (s Status) IsValid() bool {
+	switch s {
+	case Starting, Processing, Succeeded, Failed, Canceled:
+		return true
+	default:
+		return false
+	}
+}
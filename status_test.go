@@ -0,0 +1,38 @@
+package replicate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestStatus(t *testing.T) {
+	testCases := []struct {
+		status     replicate.Status
+		valid      bool
+		terminated bool
+		succeeded  bool
+		failed     bool
+		canceled   bool
+	}{
+		{status: replicate.Starting, valid: true},
+		{status: replicate.Processing, valid: true},
+		{status: replicate.Succeeded, valid: true, terminated: true, succeeded: true},
+		{status: replicate.Failed, valid: true, terminated: true, failed: true},
+		{status: replicate.Canceled, valid: true, terminated: true, canceled: true},
+		{status: replicate.Status("aborted")},
+		{status: replicate.Status("")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			assert.Equal(t, tc.valid, tc.status.IsValid())
+			assert.Equal(t, tc.terminated, tc.status.Terminated())
+			assert.Equal(t, tc.succeeded, tc.status.Succeeded())
+			assert.Equal(t, tc.failed, tc.status.Failed())
+			assert.Equal(t, tc.canceled, tc.status.Canceled())
+		})
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 	"unicode/utf8"
 
 	"github.com/vincent-petithory/dataurl"
@@ -20,6 +23,11 @@ import (
 
 var (
 	ErrInvalidUTF8Data = errors.New("invalid UTF-8 data")
+	ErrMaximumRetries  = sse.ErrMaximumRetries
+
+	// ErrStreamNotEnabled is returned when streaming a prediction that has no stream URL,
+	// because the model doesn't support streaming or the prediction was created without it.
+	ErrStreamNotEnabled = errors.New("streaming not supported or not enabled for this prediction")
 )
 
 const (
@@ -131,16 +139,122 @@ func (r *Client) Stream(ctx context.Context, identifier string, input Prediction
 		return sseChan, errChan
 	}
 
-	r.streamPrediction(ctx, prediction, nil, sseChan, errChan)
+	ctx, stop := r.streamContext(ctx)
+	r.streamPrediction(ctx, prediction, nil, sseChan, errChan, stop)
 
 	return sseChan, errChan
 }
 
+// StreamWithDeployment creates a prediction with a deployment and streams its events,
+// like Stream does for models and versions.
+func (r *Client) StreamWithDeployment(ctx context.Context, deploymentOwner string, deploymentName string, input PredictionInput, webhook *Webhook) (<-chan SSEEvent, <-chan error) {
+	sseChan := make(chan SSEEvent, 64)
+	errChan := make(chan error, 64)
+
+	prediction, err := r.CreatePredictionWithDeployment(ctx, deploymentOwner, deploymentName, input, webhook, true)
+	if err != nil {
+		r.sendError(err, errChan)
+		return sseChan, errChan
+	}
+
+	ctx, stop := r.streamContext(ctx)
+	r.streamPrediction(ctx, prediction, nil, sseChan, errChan, stop)
+
+	return sseChan, errChan
+}
+
+// StreamPrediction streams the output of an existing prediction.
+//
+// It can be used with a prediction created with streaming enabled,
+// such as with CreatePrediction and stream set to true,
+// to inspect the prediction before streaming it without creating it again.
+// If the prediction has no stream URL, ErrStreamNotEnabled is sent on the error channel.
 func (r *Client) StreamPrediction(ctx context.Context, prediction *Prediction) (<-chan SSEEvent, <-chan error) {
+	return r.StreamPredictionFrom(ctx, prediction, "")
+}
+
+// StreamPredictionFrom streams a prediction like StreamPrediction, resuming
+// after the event with the given ID.  The ID is sent in the Last-Event-ID
+// header, so a stream can be resumed after a restart by persisting the ID of
+// the last event received.  If lastEventID is empty, the stream starts from
+// the beginning.
+func (r *Client) StreamPredictionFrom(ctx context.Context, prediction *Prediction, lastEventID string) (<-chan SSEEvent, <-chan error) {
 	sseChan := make(chan SSEEvent, 64)
 	errChan := make(chan error, 64)
 
-	r.streamPrediction(ctx, prediction, nil, sseChan, errChan)
+	var lastEvent *SSEEvent
+	if lastEventID != "" {
+		lastEvent = &SSEEvent{ID: lastEventID}
+	}
+
+	ctx, stop := r.streamContext(ctx)
+	r.streamPrediction(ctx, prediction, lastEvent, sseChan, errChan, stop)
+
+	return sseChan, errChan
+}
+
+// StreamPredictionEvents streams all events of a prediction via the replicate
+// streaming api, including output, logs, error, and done events.  Each event
+// keeps its type, so callers can switch on it to render logs alongside output.
+// Event types this client doesn't know about are delivered with their type and
+// data as received, rather than ending the stream.
+// Both channels are closed after the done event is received, or if streaming
+// fails.
+func (r *Client) StreamPredictionEvents(ctx context.Context, prediction *Prediction) (<-chan SSEEvent, <-chan error) {
+	sseChan := make(chan SSEEvent, 64)
+	errChan := make(chan error, 64)
+
+	url, ok := prediction.StreamURL()
+	if !ok {
+		r.sendError(ErrStreamNotEnabled, errChan)
+		close(sseChan)
+		close(errChan)
+		return sseChan, errChan
+	}
+	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
+
+	ctx, stop := r.streamContext(ctx)
+	go func() {
+		defer close(errChan)
+		defer close(sseChan)
+		defer stop()
+		defer s.Close()
+
+		for {
+			e, err := s.NextEvent(ctx)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					r.sendError(err, errChan)
+				}
+				return
+			}
+
+			if e.Type == "" && e.Data == "" {
+				// empty message, ignore
+				// nchan starts streams with a blank `: hi` message
+				continue
+			}
+
+			event := SSEEvent{
+				Type: e.Type,
+				ID:   e.ID,
+				Data: strings.TrimSuffix(e.Data, "\n"),
+			}
+			if event.Type == "" {
+				event.Type = SSETypeDefault
+			}
+
+			select {
+			case sseChan <- event:
+			case <-ctx.Done():
+				return
+			}
+
+			if event.Type == SSETypeDone {
+				return
+			}
+		}
+	}()
 
 	return sseChan, errChan
 }
@@ -148,8 +262,21 @@ func (r *Client) StreamPrediction(ctx context.Context, prediction *Prediction) (
 type textStreamer struct {
 	s            *sse.Streamer
 	ctx          context.Context
+	stop         context.CancelFunc
 	currentEvent io.Reader
 	done         bool
+
+	// onDone is called when the done event is received, before io.EOF is returned.
+	onDone func(ctx context.Context) error
+
+	// transform is called with the data of each output event.
+	// Once it returns false, the stream ends after the returned text is read.
+	transform func(chunk string) (string, bool)
+	stopped   bool
+
+	// handler is called with events other than output, done, and error.
+	// If it's nil, they're skipped.
+	handler func(SSEEvent)
 }
 
 func (t *textStreamer) Read(buf []byte) (int, error) {
@@ -169,13 +296,28 @@ func (t *textStreamer) Read(buf []byte) (int, error) {
 				continue
 			case SSETypeDone:
 				t.done = true
+				if t.onDone != nil {
+					if err := t.onDone(t.ctx); err != nil {
+						return 0, err
+					}
+				}
 				return 0, io.EOF
 			case SSETypeError:
 				return 0, fmt.Errorf("Error event: %s", e.Data)
 			case SSETypeOutput:
-				t.currentEvent = strings.NewReader(strings.TrimSuffix(e.Data, "\n"))
+				chunk := strings.TrimSuffix(e.Data, "\n")
+				if t.transform != nil {
+					var more bool
+					chunk, more = t.transform(chunk)
+					t.stopped = !more
+				}
+				t.currentEvent = strings.NewReader(chunk)
 			default:
-				return 0, fmt.Errorf("unexpected type %s, %+v", e.Type, e)
+				// Other events, such as logs, aren't part of the text
+				if t.handler != nil {
+					t.handler(SSEEvent{Type: e.Type, ID: e.ID, Data: strings.TrimSuffix(e.Data, "\n")})
+				}
+				continue
 			}
 		}
 
@@ -187,9 +329,17 @@ func (t *textStreamer) Read(buf []byte) (int, error) {
 
 		if err == io.EOF {
 			t.currentEvent = nil
+			if t.stopped && !t.done {
+				// the rest of the stream isn't needed, so disconnect early
+				t.done = true
+				t.s.Close()
+			}
 			if n > 0 {
 				return n, nil
 			}
+			if t.done {
+				return 0, io.EOF
+			}
 			// we haven't got any data, try to fetch the next event
 			continue
 		}
@@ -199,21 +349,134 @@ func (t *textStreamer) Read(buf []byte) (int, error) {
 }
 
 func (t *textStreamer) Close() error {
+	t.stop()
 	return t.s.Close()
 }
 
 // StreamPredictionText streams prediction text output via the replicate
 // streaming api.  It is the caller's responsibility to close the returned
 // io.ReadCloser to ensure connections and associated resources are cleaned up
-// appropriately.
+// appropriately.  If the context is canceled, the text received up to that
+// point can still be read before the context's error is returned.
 func (r *Client) StreamPredictionText(ctx context.Context, prediction *Prediction) (io.ReadCloser, error) {
-	url := prediction.URLs["stream"]
-	if url == "" {
-		return nil, errors.New("streaming not supported or not enabled for this prediction")
+	url, ok := prediction.StreamURL()
+	if !ok {
+		return nil, ErrStreamNotEnabled
 	}
 	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
 
-	return &textStreamer{s: s, ctx: ctx}, nil
+	ctx, stop := r.streamContext(ctx)
+	return &textStreamer{s: s, ctx: ctx, stop: stop, handler: r.options.streamEventHandler}, nil
+}
+
+// StreamPredictionTextFunc streams prediction text output like StreamPredictionText,
+// passing each chunk of output through fn.
+// The text fn returns is read in place of the chunk.
+// If fn returns false, the stream ends after the returned text,
+// which can be used to implement stop sequences or to strip unwanted characters.
+// It is the caller's responsibility to close the returned io.ReadCloser.
+func (r *Client) StreamPredictionTextFunc(ctx context.Context, prediction *Prediction, fn func(chunk string) (string, bool)) (io.ReadCloser, error) {
+	url, ok := prediction.StreamURL()
+	if !ok {
+		return nil, ErrStreamNotEnabled
+	}
+	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
+
+	ctx, stop := r.streamContext(ctx)
+	return &textStreamer{s: s, ctx: ctx, stop: stop, transform: fn, handler: r.options.streamEventHandler}, nil
+}
+
+// StreamPredictionTextTo streams prediction text output like StreamPredictionText,
+// writing each chunk of output to w as it's received,
+// such as os.Stdout or an http.ResponseWriter.
+// If w implements http.Flusher, it's flushed after each write,
+// so the output reaches clients of an HTTP response without delay.
+//
+// It returns once the done event is received, or when streaming or writing fails.
+func (r *Client) StreamPredictionTextTo(ctx context.Context, prediction *Prediction, w io.Writer) error {
+	text, err := r.StreamPredictionText(ctx, prediction)
+	if err != nil {
+		return err
+	}
+	defer text.Close()
+
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := text.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// StreamPredictionJSON streams a prediction whose output events are fragments of a JSON document.
+// It concatenates the data of every output event,
+// and once the done event is received, unmarshals the result into v.
+// If the concatenated output isn't valid JSON, an error is returned.
+func (r *Client) StreamPredictionJSON(ctx context.Context, prediction *Prediction, v interface{}) error {
+	text, err := r.StreamPredictionText(ctx, prediction)
+	if err != nil {
+		return err
+	}
+	defer text.Close()
+
+	data, err := io.ReadAll(text)
+	if err != nil {
+		return fmt.Errorf("failed to stream prediction output: %w", err)
+	}
+
+	if !json.Valid(data) {
+		return fmt.Errorf("streamed prediction output of %d bytes is not valid JSON", len(data))
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal streamed prediction output: %w", err)
+	}
+
+	return nil
+}
+
+// StreamPredictionTextWithMetrics streams prediction text output like
+// StreamPredictionText, and also returns a function that reports the metrics of
+// the finished prediction.  The function returns nil until the returned
+// io.ReadCloser has been fully drained, at which point the final prediction
+// state is fetched to populate the metrics.  It is the caller's responsibility
+// to close the returned io.ReadCloser.
+func (r *Client) StreamPredictionTextWithMetrics(ctx context.Context, prediction *Prediction) (io.ReadCloser, func() *PredictionMetrics, error) {
+	url, ok := prediction.StreamURL()
+	if !ok {
+		return nil, nil, ErrStreamNotEnabled
+	}
+	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
+
+	var metrics atomic.Pointer[PredictionMetrics]
+	onDone := func(ctx context.Context) error {
+		final, err := r.GetPrediction(ctx, prediction.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get prediction metrics: %w", err)
+		}
+		if final.Metrics == nil {
+			metrics.Store(&PredictionMetrics{})
+		} else {
+			metrics.Store(final.Metrics)
+		}
+		return nil
+	}
+
+	ctx, stop := r.streamContext(ctx)
+	return &textStreamer{s: s, ctx: ctx, stop: stop, onDone: onDone, handler: r.options.streamEventHandler}, metrics.Load, nil
 }
 
 type dataURL struct {
@@ -235,32 +498,76 @@ func (d *dataURL) Body(_ context.Context) (io.ReadCloser, error) {
 type httpURL struct {
 	c   *http.Client
 	url string
+
+	// timeout limits fetching the file, including reading its body.
+	// If it's zero, there's no limit.
+	timeout time.Duration
 }
 
 var _ streaming.File = &httpURL{}
 
 func (h *httpURL) Body(ctx context.Context) (io.ReadCloser, error) {
+	cancel := context.CancelFunc(func() {})
+	if h.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
 	resp, err := h.c.Do(req)
 	if err != nil {
+		cancel()
 		return nil, err
 	}
-	return resp.Body, nil
+	return &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// cancelOnClose is an io.ReadCloser that cancels a context once it's closed,
+// so the context of a request lasts as long as its response body is read.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
 }
 
 type fileStreamer struct {
-	s    *sse.Streamer
-	c    *http.Client
-	done bool
+	s       *sse.Streamer
+	c       *http.Client
+	r       *Client
+	done    bool
+	options streamFilesOptions
+}
+
+type streamFilesOptions struct {
+	fileTimeout time.Duration
+}
+
+// StreamFilesOption is a function that modifies the options of StreamPredictionFiles.
+type StreamFilesOption func(*streamFilesOptions)
+
+// WithFileTimeout limits the time to fetch each file served over HTTP,
+// including reading its body, so a stalled file server doesn't block the stream indefinitely.
+// A value of zero means there is no limit.
+func WithFileTimeout(timeout time.Duration) StreamFilesOption {
+	return func(o *streamFilesOptions) {
+		o.fileTimeout = timeout
+	}
 }
 
 func (f *fileStreamer) NextFile(ctx context.Context) (streaming.File, error) {
 	if f.done {
 		return nil, io.EOF
 	}
+	ctx, stop := f.r.streamContext(ctx)
+	defer stop()
+
 	for {
 		var url string
 		e, err := f.s.NextEvent(ctx)
@@ -280,14 +587,18 @@ func (f *fileStreamer) NextFile(ctx context.Context) (streaming.File, error) {
 		case SSETypeOutput:
 			url = strings.TrimSuffix(e.Data, "\n")
 		default:
-			return nil, fmt.Errorf("unexpected type %s, %+v", e.Type, e)
+			// Other events, such as logs, aren't files
+			if f.r.options.streamEventHandler != nil {
+				f.r.options.streamEventHandler(SSEEvent{Type: e.Type, ID: e.ID, Data: strings.TrimSuffix(e.Data, "\n")})
+			}
+			continue
 		}
 
 		switch {
 		case strings.HasPrefix(url, "data:"):
 			return &dataURL{url: url}, nil
 		case strings.HasPrefix(url, "http"):
-			return &httpURL{c: f.c, url: url}, nil
+			return &httpURL{c: f.c, url: url, timeout: f.options.fileTimeout}, nil
 		default:
 			return nil, fmt.Errorf("Could not parse URL: %s", url)
 		}
@@ -302,27 +613,94 @@ func (f *fileStreamer) Close() error {
 // streaming api.  It is the caller's responsibility to close the returned
 // FileStreamer to ensure connections and associated resources are cleaned up
 // appropriately.
-func (r *Client) StreamPredictionFiles(prediction *Prediction) (streaming.FileStreamer, error) {
-	url := prediction.URLs["stream"]
-	if url == "" {
-		return nil, errors.New("streaming not supported or not enabled for this prediction")
+//
+// Files served over HTTP are fetched with the client's http.Client.
+// Use WithFileTimeout to limit the time each file takes.
+func (r *Client) StreamPredictionFiles(prediction *Prediction, opts ...StreamFilesOption) (streaming.FileStreamer, error) {
+	url, ok := prediction.StreamURL()
+	if !ok {
+		return nil, ErrStreamNotEnabled
+	}
+
+	options := streamFilesOptions{}
+	for _, opt := range opts {
+		opt(&options)
 	}
 
 	s := sse.NewStreamer(r.c, url, r.options.retryPolicy.maxRetries, r.options.retryPolicy.backoff)
-	return &fileStreamer{s: s, c: r.c}, nil
+	return &fileStreamer{s: s, c: r.c, r: r, options: options}, nil
 }
 
-func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, lastEvent *SSEEvent, sseChan chan SSEEvent, errChan chan error) {
-	url := prediction.URLs["stream"]
-	if url == "" {
-		r.sendError(errors.New("streaming not supported or not enabled for this prediction"), errChan)
+// streamPrediction streams the events of a prediction to sseChan.
+//
+// If the connection is closed before the done event is received, it reconnects
+// using the client's retry policy, resuming after the last received event.
+// The number of attempts is reset once an event is received on a new connection,
+// so only consecutive failed connections count toward the maximum number of retries.
+// Both channels are closed and stop is called when streaming finishes.
+func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, lastEvent *SSEEvent, sseChan chan SSEEvent, errChan chan error, stop context.CancelFunc) {
+	closeChannels := func() {
+		stop()
+		close(sseChan)
+		close(errChan)
+	}
+
+	url, ok := prediction.StreamURL()
+	if !ok {
+		r.sendError(ErrStreamNotEnabled, errChan)
+		closeChannels()
 		return
 	}
 
+	go func() {
+		defer closeChannels()
+
+		attempt := 0
+		for {
+			var received bool
+			var err error
+			lastEvent, received, err = r.streamPredictionEvents(ctx, url, lastEvent, sseChan, errChan)
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			if received {
+				attempt = 0
+			}
+
+			// Wait for the retry policy's backoff delay and stream again
+			// from the last received event, or give up after the maximum number of retries.
+			maxRetries := r.options.retryPolicy.maxRetries
+			if attempt >= maxRetries {
+				r.sendError(fmt.Errorf("%w: stream closed after %d reconnect attempts: %w", ErrMaximumRetries, attempt, err), errChan)
+				return
+			}
+
+			backoff := r.options.retryPolicy.backoff.NextDelay(attempt)
+			r.options.logger.Warnf("reconnecting to stream for prediction %s after %v, delay=%s, attempt=%d", prediction.ID, err, backoff, attempt+1)
+			attempt++
+
+			delay := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				delay.Stop()
+				return
+			case <-delay.C:
+			}
+		}
+	}()
+}
+
+// streamPredictionEvents reads events from one connection to a prediction's stream
+// and sends them to sseChan, resuming after lastEvent if it isn't nil.
+//
+// It returns the last received event with an ID, and whether any events were received.
+// If the connection failed or was closed before the done event, it returns an error,
+// and the stream can be resumed. Other errors are sent to errChan.
+func (r *Client) streamPredictionEvents(ctx context.Context, url string, lastEvent *SSEEvent, sseChan chan SSEEvent, errChan chan error) (*SSEEvent, bool, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		r.sendError(fmt.Errorf("failed to create request: %w", err), errChan)
-		return
+		return lastEvent, false, nil
 	}
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
@@ -334,20 +712,21 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 
 	resp, err := r.c.Do(req)
 	if err != nil {
-		r.sendError(fmt.Errorf("failed to send request: %w", err), errChan)
-		return
+		return lastEvent, false, fmt.Errorf("failed to send request: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		r.sendError(fmt.Errorf("received invalid status code: %d", resp.StatusCode), errChan)
-		return
+		return lastEvent, false, nil
 	}
 
 	reader := bufio.NewReader(resp.Body)
 	var buf bytes.Buffer
 	lineChan := make(chan []byte)
+	received := false
 
-	g, ctx := errgroup.WithContext(ctx)
+	g, gctx := errgroup.WithContext(ctx)
 	done := make(chan struct{})
 
 	g.Go(func() error {
@@ -356,8 +735,8 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 
 		for {
 			select {
-			case <-ctx.Done():
-				return ctx.Err()
+			case <-gctx.Done():
+				return gctx.Err()
 			case <-done:
 				return nil
 			default:
@@ -367,23 +746,27 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 				}
 				select {
 				case lineChan <- line:
-				case <-ctx.Done():
-					return ctx.Err()
+				case <-done:
+					return nil
+				case <-gctx.Done():
+					return gctx.Err()
 				}
 			}
 		}
 	})
 
-	go func() {
+	// The event loop watches the caller's context rather than the group's,
+	// so events read before the connection closed are still delivered.
+	g.Go(func() error {
 		for {
 			select {
 			case <-ctx.Done():
-				return
+				return nil
 			case <-done:
-				return
+				return nil
 			case b, ok := <-lineChan:
 				if !ok {
-					return
+					return nil
 				}
 
 				buf.Write(b)
@@ -403,45 +786,42 @@ func (r *Client) streamPrediction(ctx context.Context, prediction *Prediction, l
 						continue
 					}
 
+					received = true
+					if event.ID != "" {
+						lastEvent = event
+					}
+
 					select {
 					case sseChan <- *event:
 					case <-done:
-						return
+						return nil
 					case <-ctx.Done():
-						return
+						return nil
 					}
 
 					if event.Type == SSETypeDone {
 						close(done)
-						return
+						return nil
 					}
 				}
 			}
 		}
-	}()
-
-	go func() {
-		err := g.Wait()
-
-		if err != nil {
-			if errors.Is(err, io.EOF) {
-				select {
-				case <-done:
-					// if we get EOF after receiving "done", we're done
-					return
-				default:
-				}
-				// Attempt to reconnect if the connection was closed before the stream was done
-				r.streamPrediction(ctx, prediction, lastEvent, sseChan, errChan)
-				return
-			}
+	})
 
-			if !errors.Is(err, context.Canceled) {
-				r.sendError(err, errChan)
+	err = g.Wait()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			select {
+			case <-done:
+				// if we get EOF after receiving "done", we're done
+			default:
+				// The connection was closed before the stream was done
+				return lastEvent, received, err
 			}
+		} else if !errors.Is(err, context.Canceled) {
+			r.sendError(err, errChan)
 		}
+	}
 
-		close(sseChan)
-		close(errChan)
-	}()
+	return lastEvent, received, nil
 }
@@ -2,10 +2,12 @@ package replicate_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -84,6 +86,575 @@ event: done
 	assert.Equal(t, "foo", string(text))
 }
 
+func TestStreamTextCanceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The second event is never finished with a blank line
+		fmt.Fprint(w, `event: output
+data: foo
+
+event: output
+data: bar
+`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	r, err := c.StreamPredictionText(ctx, p)
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(r, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(buf))
+
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	text, err := io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "bar", string(text))
+}
+
+func TestStreamNotEnabled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"get": "https://api.replicate.com/v1/predictions/ufawqhfynnddngldkgtslldrkq",
+		},
+	}
+	_, ok := p.StreamURL()
+	assert.False(t, ok)
+
+	_, err = c.StreamPredictionText(ctx, p)
+	assert.ErrorIs(t, err, replicate.ErrStreamNotEnabled)
+
+	sseChan, errChan := c.StreamPrediction(ctx, p)
+	for range sseChan {
+		t.Fatal("Unexpected event")
+	}
+	assert.ErrorIs(t, <-errChan, replicate.ErrStreamNotEnabled)
+
+	p.URLs["stream"] = "https://streaming-api.svc.us.c.replicate.net/v1/streams/abc"
+	url, ok := p.StreamURL()
+	assert.True(t, ok)
+	assert.Equal(t, "https://streaming-api.svc.us.c.replicate.net/v1/streams/abc", url)
+}
+
+func TestStreamPredictionTextFunc(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `event: output
+data: Hello,
+
+event: output
+data:  \033[1mworld\033[0m
+
+event: output
+data: ! ###
+
+event: output
+data: Never read
+
+`)
+		w.(http.Flusher).Flush()
+
+		// Hold the stream open, so it only ends if the client disconnects
+		<-r.Context().Done()
+	}))
+	t.Cleanup(ts.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	var chunks []string
+	r, err := c.StreamPredictionTextFunc(ctx, p, func(chunk string) (string, bool) {
+		chunks = append(chunks, chunk)
+		chunk = strings.NewReplacer(`\033[1m`, "", `\033[0m`, "").Replace(chunk)
+		before, found := strings.CutSuffix(chunk, " ###")
+		return before, !found
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	text, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, world!", string(text))
+	assert.Len(t, chunks, 3)
+}
+
+// flushRecorder records what's written to it, and the data at each flush.
+type flushRecorder struct {
+	strings.Builder
+	flushed []string
+}
+
+func (f *flushRecorder) Flush() {
+	f.flushed = append(f.flushed, f.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestStreamPredictionTextTo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `event: output
+data: Hello,
+
+event: output
+data:  world!
+
+event: done
+data: {}
+
+`)
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	t.Run("writer", func(t *testing.T) {
+		var buf strings.Builder
+		err := c.StreamPredictionTextTo(ctx, p, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, world!", buf.String())
+	})
+
+	t.Run("flusher", func(t *testing.T) {
+		w := &flushRecorder{}
+		err := c.StreamPredictionTextTo(ctx, p, w)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, world!", w.String())
+		assert.Equal(t, []string{"Hello,", "Hello, world!"}, w.flushed)
+	})
+
+	t.Run("write error", func(t *testing.T) {
+		err := c.StreamPredictionTextTo(ctx, p, failingWriter{})
+		assert.ErrorIs(t, err, io.ErrClosedPipe)
+	})
+
+	t.Run("stream not enabled", func(t *testing.T) {
+		err := c.StreamPredictionTextTo(ctx, &replicate.Prediction{}, &strings.Builder{})
+		assert.ErrorIs(t, err, replicate.ErrStreamNotEnabled)
+	})
+}
+
+func TestStreamPredictionJSON(t *testing.T) {
+	newPrediction := func(t *testing.T, body string) *replicate.Prediction {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprint(w, body)
+		}))
+		t.Cleanup(ts.Close)
+
+		return &replicate.Prediction{
+			URLs: map[string]string{
+				"stream": ts.URL,
+			},
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	t.Run("valid", func(t *testing.T) {
+		p := newPrediction(t, `event: output
+data: {"name": "Al
+
+event: output
+data: ice", "scores": [1,
+
+event: output
+data:  2]}
+
+event: done
+
+`)
+
+		var result struct {
+			Name   string `json:"name"`
+			Scores []int  `json:"scores"`
+		}
+		err := c.StreamPredictionJSON(ctx, p, &result)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", result.Name)
+		assert.Equal(t, []int{1, 2}, result.Scores)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		p := newPrediction(t, `event: output
+data: {"name": "Al
+
+event: done
+
+`)
+
+		var result map[string]interface{}
+		err := c.StreamPredictionJSON(ctx, p, &result)
+		assert.ErrorContains(t, err, "not valid JSON")
+	})
+}
+
+func TestStreamTextWithMetrics(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/predictions/ufawqhfynnddngldkgtslldrkq":
+			tokensPerSecond := 42.5
+			outputTokenCount := 2
+			json.NewEncoder(w).Encode(replicate.Prediction{
+				ID:     "ufawqhfynnddngldkgtslldrkq",
+				Status: replicate.Succeeded,
+				Metrics: &replicate.PredictionMetrics{
+					OutputTokenCount: &outputTokenCount,
+					TokensPerSecond:  &tokensPerSecond,
+				},
+			})
+		case "/stream":
+			fmt.Fprint(w, `event: output
+data: foo
+
+event: output
+data: bar
+
+event: done
+
+`)
+		default:
+			t.Fatalf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		ID: "ufawqhfynnddngldkgtslldrkq",
+		URLs: map[string]string{
+			"stream": ts.URL + "/stream",
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"), replicate.WithBaseURL(ts.URL))
+	require.NoError(t, err)
+
+	r, metrics, err := c.StreamPredictionTextWithMetrics(ctx, p)
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	assert.Nil(t, metrics())
+
+	text, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "foobar", string(text))
+
+	m := metrics()
+	require.NotNil(t, m)
+	assert.Equal(t, 2, *m.OutputTokenCount)
+	assert.Equal(t, 42.5, *m.TokensPerSecond)
+}
+
+func TestStreamPredictionReconnects(t *testing.T) {
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("Last-Event-ID"))
+
+		// the connection is always closed before the done event,
+		// and no more events are sent after the third connection
+		if len(requests) <= 3 {
+			fmt.Fprintf(w, "event: output\nid: %d\ndata: foo\n\n", len(requests))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(
+		replicate.WithToken("test-token"),
+		replicate.WithRetryPolicy(2, &replicate.ConstantBackoff{Base: 10 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+
+	sseChan, errChan := c.StreamPrediction(ctx, p)
+
+	var events []replicate.SSEEvent
+	for event := range sseChan {
+		events = append(events, event)
+	}
+
+	err = <-errChan
+	assert.ErrorIs(t, err, replicate.ErrMaximumRetries)
+
+	// Connections that received an event don't count toward the maximum number of retries
+	assert.Len(t, events, 3)
+	assert.Equal(t, []string{"", "1", "2", "3", "3"}, requests)
+}
+
+func TestStreamPredictionFrom(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "41", r.Header.Get("Last-Event-ID"))
+
+		fmt.Fprint(w, "event: output\nid: 42\ndata: bar\n\nevent: done\nid: 43\ndata: {}\n\n")
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	sseChan, errChan := c.StreamPredictionFrom(ctx, p, "41")
+
+	var events []replicate.SSEEvent
+	for event := range sseChan {
+		events = append(events, event)
+	}
+	assert.NoError(t, <-errChan)
+
+	require.Len(t, events, 2)
+	assert.Equal(t, "42", events[0].ID)
+	assert.Equal(t, "bar", events[0].Data)
+	assert.Equal(t, replicate.SSETypeDone, events[1].Type)
+}
+
+func TestStreamPredictionEvents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `: hi
+
+event: logs
+data: Loading model
+
+event: output
+data: foo
+
+event: logs
+data: Generated 1 token
+
+event: output
+data: bar
+
+event: done
+data: {}
+
+`)
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	sseChan, errChan := c.StreamPredictionEvents(ctx, p)
+
+	var events []replicate.SSEEvent
+	for event := range sseChan {
+		events = append(events, event)
+	}
+	require.NoError(t, <-errChan)
+
+	assert.Equal(t, []replicate.SSEEvent{
+		{Type: replicate.SSETypeLogs, Data: "Loading model"},
+		{Type: replicate.SSETypeOutput, Data: "foo"},
+		{Type: replicate.SSETypeLogs, Data: "Generated 1 token"},
+		{Type: replicate.SSETypeOutput, Data: "bar"},
+		{Type: replicate.SSETypeDone, Data: "{}"},
+	}, events)
+}
+
+func TestStreamUnknownEventTypes(t *testing.T) {
+	const body = `event: logs
+data: Loading model
+
+event: output
+data: The weather is
+
+event: tool_call
+data: {"name": "get_weather"}
+
+event: output
+data:  sunny
+
+event: done
+data: {}
+
+`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	t.Run("events", func(t *testing.T) {
+		c, err := replicate.NewClient(replicate.WithToken("test-token"))
+		require.NoError(t, err)
+
+		sseChan, errChan := c.StreamPredictionEvents(ctx, p)
+
+		var events []replicate.SSEEvent
+		for event := range sseChan {
+			events = append(events, event)
+		}
+		require.NoError(t, <-errChan)
+		require.Len(t, events, 5)
+		assert.Equal(t, replicate.SSEEvent{Type: "tool_call", Data: `{"name": "get_weather"}`}, events[2])
+	})
+
+	t.Run("text without handler", func(t *testing.T) {
+		c, err := replicate.NewClient(replicate.WithToken("test-token"))
+		require.NoError(t, err)
+
+		r, err := c.StreamPredictionText(ctx, p)
+		require.NoError(t, err)
+		t.Cleanup(func() { r.Close() })
+
+		text, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "The weather is sunny", string(text))
+	})
+
+	t.Run("text with handler", func(t *testing.T) {
+		var handled []replicate.SSEEvent
+		c, err := replicate.NewClient(
+			replicate.WithToken("test-token"),
+			replicate.WithStreamEventHandler(func(event replicate.SSEEvent) {
+				handled = append(handled, event)
+			}),
+		)
+		require.NoError(t, err)
+
+		r, err := c.StreamPredictionText(ctx, p)
+		require.NoError(t, err)
+		t.Cleanup(func() { r.Close() })
+
+		text, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, "The weather is sunny", string(text))
+		assert.Equal(t, []replicate.SSEEvent{
+			{Type: replicate.SSETypeLogs, Data: "Loading model"},
+			{Type: "tool_call", Data: `{"name": "get_weather"}`},
+		}, handled)
+	})
+}
+
+func TestClientCloseStopsStreams(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `event: output
+data: foo
+
+`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	t.Cleanup(ts.Close)
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	sseChan, errChan := c.StreamPredictionEvents(ctx, p)
+
+	event := <-sseChan
+	assert.Equal(t, "foo", event.Data)
+
+	require.NoError(t, c.Close())
+
+	for range sseChan { //nolint:all
+	}
+	for err := range errChan {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, ctx.Err(), "stream should stop before the context is done")
+
+	// Streams started after Close stop immediately
+	r, err := c.StreamPredictionText(ctx, p)
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Close can be called more than once
+	assert.NoError(t, c.Close())
+}
+
 func TestStreamFiles(t *testing.T) {
 	var baseURL string
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -150,3 +721,65 @@ event: done
 	require.NoError(t, err)
 	assert.Equal(t, "mango\n", string(content3))
 }
+
+func TestStreamFilesWithFileTimeout(t *testing.T) {
+	var baseURL string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hang":
+			// Never respond
+			<-r.Context().Done()
+		case "/stall":
+			// Respond, but stall partway through the body
+			w.Write([]byte("man"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			fmt.Fprintf(w, `event: output
+data: %s/hang
+
+event: output
+data: %s/stall
+
+event: done
+
+`, baseURL, baseURL)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	baseURL = ts.URL
+
+	p := &replicate.Prediction{
+		URLs: map[string]string{
+			"stream": ts.URL,
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+
+	c, err := replicate.NewClient(replicate.WithToken("test-token"))
+	require.NoError(t, err)
+
+	files, err := c.StreamPredictionFiles(p, replicate.WithFileTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { files.Close() })
+
+	file, err := files.NextFile(ctx)
+	require.NoError(t, err)
+	_, err = file.Body(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	file, err = files.NextFile(ctx)
+	require.NoError(t, err)
+	body, err := file.Body(ctx)
+	require.NoError(t, err)
+	_, err = io.ReadAll(body)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NoError(t, body.Close())
+
+	// The stream itself isn't affected by the timeout
+	assert.NoError(t, ctx.Err())
+	_, err = files.NextFile(ctx)
+	assert.ErrorIs(t, err, io.EOF)
+}
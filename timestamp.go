@@ -0,0 +1,87 @@
+package replicate
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrTimestampNotSet = errors.New("timestamp is not set")
+)
+
+// parseTimestamp parses an RFC 3339 timestamp returned by the API.
+// If the timestamp is nil or empty, an error wrapping ErrTimestampNotSet is returned.
+func parseTimestamp(name string, value *string) (time.Time, error) {
+	if value == nil || *value == "" {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrTimestampNotSet, name)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, *value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return t, nil
+}
+
+// CreatedAtTime returns the time the prediction was created.
+func (p *Prediction) CreatedAtTime() (time.Time, error) {
+	return parseTimestamp("created_at", &p.CreatedAt)
+}
+
+// StartedAtTime returns the time the prediction started running.
+func (p *Prediction) StartedAtTime() (time.Time, error) {
+	return parseTimestamp("started_at", p.StartedAt)
+}
+
+// CompletedAtTime returns the time the prediction finished.
+func (p *Prediction) CompletedAtTime() (time.Time, error) {
+	return parseTimestamp("completed_at", p.CompletedAt)
+}
+
+// CreatedAtTime returns the time the file was created.
+func (f *File) CreatedAtTime() (time.Time, error) {
+	return parseTimestamp("created_at", &f.CreatedAt)
+}
+
+// ExpiresAtTime returns the time the file expires.
+func (f *File) ExpiresAtTime() (time.Time, error) {
+	return parseTimestamp("expires_at", &f.ExpiresAt)
+}
+
+// CreatedAtTime returns the time the model version was created.
+func (m *ModelVersion) CreatedAtTime() (time.Time, error) {
+	return parseTimestamp("created_at", &m.CreatedAt)
+}
+
+// QueueDuration returns how long the prediction waited before it started running,
+// including any cold start.
+func (p *Prediction) QueueDuration() (time.Duration, error) {
+	createdAt, err := p.CreatedAtTime()
+	if err != nil {
+		return 0, err
+	}
+
+	startedAt, err := p.StartedAtTime()
+	if err != nil {
+		return 0, err
+	}
+
+	return startedAt.Sub(createdAt), nil
+}
+
+// RunDuration returns how long the prediction ran before it finished.
+func (p *Prediction) RunDuration() (time.Duration, error) {
+	startedAt, err := p.StartedAtTime()
+	if err != nil {
+		return 0, err
+	}
+
+	completedAt, err := p.CompletedAtTime()
+	if err != nil {
+		return 0, err
+	}
+
+	return completedAt.Sub(startedAt), nil
+}
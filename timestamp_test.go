@@ -0,0 +1,76 @@
+package replicate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/replicate/replicate-go"
+)
+
+func TestPredictionTimestamps(t *testing.T) {
+	startedAt := "2022-04-26T22:13:06.324088Z"
+	prediction := &replicate.Prediction{
+		CreatedAt: "2022-04-26T22:13:06.224088Z",
+		StartedAt: &startedAt,
+	}
+
+	createdAt, err := prediction.CreatedAtTime()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 4, 26, 22, 13, 6, 224088000, time.UTC), createdAt)
+
+	started, err := prediction.StartedAtTime()
+	require.NoError(t, err)
+	assert.Equal(t, 100*time.Millisecond, started.Sub(createdAt))
+
+	_, err = prediction.CompletedAtTime()
+	assert.ErrorIs(t, err, replicate.ErrTimestampNotSet)
+
+	prediction.CreatedAt = "yesterday"
+	_, err = prediction.CreatedAtTime()
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, replicate.ErrTimestampNotSet)
+}
+
+func TestPredictionDurations(t *testing.T) {
+	startedAt := "2022-04-26T22:13:08.224088Z"
+	completedAt := "2022-04-26T22:13:09.724088Z"
+	prediction := &replicate.Prediction{
+		CreatedAt: "2022-04-26T22:13:06.224088Z",
+	}
+
+	_, err := prediction.QueueDuration()
+	assert.ErrorIs(t, err, replicate.ErrTimestampNotSet)
+
+	prediction.StartedAt = &startedAt
+	queue, err := prediction.QueueDuration()
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, queue)
+
+	_, err = prediction.RunDuration()
+	assert.ErrorIs(t, err, replicate.ErrTimestampNotSet)
+
+	prediction.CompletedAt = &completedAt
+	run, err := prediction.RunDuration()
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, run)
+}
+
+func TestFileTimestamps(t *testing.T) {
+	file := &replicate.File{
+		CreatedAt: "2024-01-01T00:00:00Z",
+		ExpiresAt: "2024-01-02T00:00:00Z",
+	}
+
+	createdAt, err := file.CreatedAtTime()
+	require.NoError(t, err)
+	expiresAt, err := file.ExpiresAtTime()
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, expiresAt.Sub(createdAt))
+
+	version := &replicate.ModelVersion{}
+	_, err = version.CreatedAtTime()
+	assert.ErrorIs(t, err, replicate.ErrTimestampNotSet)
+}
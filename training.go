@@ -2,6 +2,7 @@ package replicate
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -9,23 +10,90 @@ import (
 type Training Prediction
 type TrainingInput PredictionInput
 
-// CreateTraining sends a request to the Replicate API to create a new training.
-func (r *Client) CreateTraining(ctx context.Context, modelOwner string, modelName string, version string, destination string, input TrainingInput, webhook *Webhook) (*Training, error) {
-	data := map[string]interface{}{
-		"version":     version,
-		"destination": destination,
-		"input":       input,
+// Progress returns the most recent progress reported in the training's logs,
+// or nil if there is none, like Prediction.Progress.
+func (t Training) Progress(opts ...ProgressOption) *PredictionProgress {
+	return Prediction(t).Progress(opts...)
+}
+
+// CreateTrainingOption is a function that modifies createTrainingOptions.
+type CreateTrainingOption func(*createTrainingOptions)
+
+// createTrainingOptions represents options for creating a training
+type createTrainingOptions struct {
+	modelOwner string
+	modelName  string
+	version    string
+
+	destination string
+
+	input   TrainingInput
+	webhook *Webhook
+}
+
+// WithTrainingVersion trains a specific version of a model.
+func WithTrainingVersion(modelOwner string, modelName string, version string) CreateTrainingOption {
+	return func(o *createTrainingOptions) {
+		o.modelOwner = modelOwner
+		o.modelName = modelName
+		o.version = version
+	}
+}
+
+// WithTrainingDestination sets the model that the trained version is pushed to, as "owner/name".
+func WithTrainingDestination(destination string) CreateTrainingOption {
+	return func(o *createTrainingOptions) {
+		o.destination = destination
 	}
+}
+
+// WithTrainingInput sets the input of the training.
+func WithTrainingInput(input TrainingInput) CreateTrainingOption {
+	return func(o *createTrainingOptions) {
+		o.input = input
+	}
+}
 
+// WithTrainingWebhook sets the webhook called as the training progresses.
+// If webhook is nil, the client's default webhook is used.
+func WithTrainingWebhook(webhook *Webhook) CreateTrainingOption {
+	return func(o *createTrainingOptions) {
+		o.webhook = webhook
+	}
+}
+
+// CreateTrainingWithOptions creates a training with the given options.
+// WithTrainingVersion and WithTrainingDestination are required.
+func (r *Client) CreateTrainingWithOptions(ctx context.Context, opts ...CreateTrainingOption) (*Training, error) {
+	options := &createTrainingOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.modelOwner == "" || options.modelName == "" || options.version == "" {
+		return nil, errors.New("WithTrainingVersion must be set with a model owner, name, and version")
+	}
+	if options.destination == "" {
+		return nil, errors.New("WithTrainingDestination must be set")
+	}
+
+	webhook := r.webhookOrDefault(options.webhook)
 	if webhook != nil {
-		data["webhook"] = webhook.URL
-		if len(webhook.Events) > 0 {
-			data["webhook_events_filter"] = webhook.Events
+		if err := webhook.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid webhook: %w", err)
 		}
 	}
 
+	data := map[string]interface{}{
+		"version":     options.version,
+		"destination": options.destination,
+		"input":       options.input,
+	}
+
+	webhook.addToRequestBody(data)
+
 	training := &Training{}
-	path := fmt.Sprintf("/models/%s/%s/versions/%s/trainings", modelOwner, modelName, version)
+	path := fmt.Sprintf("/models/%s/%s/versions/%s/trainings", options.modelOwner, options.modelName, options.version)
 	err := r.fetch(ctx, http.MethodPost, path, data, training)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create training: %w", err)
@@ -34,6 +102,18 @@ func (r *Client) CreateTraining(ctx context.Context, modelOwner string, modelNam
 	return training, nil
 }
 
+// CreateTraining sends a request to the Replicate API to create a new training.
+//
+// Deprecated: Use CreateTrainingWithOptions, which can be extended without changing its signature.
+func (r *Client) CreateTraining(ctx context.Context, modelOwner string, modelName string, version string, destination string, input TrainingInput, webhook *Webhook) (*Training, error) {
+	return r.CreateTrainingWithOptions(ctx,
+		WithTrainingVersion(modelOwner, modelName, version),
+		WithTrainingDestination(destination),
+		WithTrainingInput(input),
+		WithTrainingWebhook(webhook),
+	)
+}
+
 // ListTrainings returns a list of trainings.
 func (r *Client) ListTrainings(ctx context.Context) (*Page[Training], error) {
 	response := &Page[Training]{}
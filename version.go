@@ -0,0 +1,55 @@
+package replicate
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+const modulePath = "github.com/replicate/replicate-go"
+
+// version is the version of the client.
+// It can be set at build time with
+// -ldflags "-X github.com/replicate/replicate-go.version=v1.2.3".
+// Otherwise, it's read from the build information of the program.
+var version = ""
+
+// Version returns the version of the client, such as "v1.2.3".
+// It returns "devel" if the version isn't known,
+// like when the client is built from a local checkout.
+func Version() string {
+	if version != "" {
+		return version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	var module *debug.Module
+	if info.Main.Path == modulePath {
+		module = &info.Main
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			module = dep
+			break
+		}
+	}
+	if module == nil {
+		return "devel"
+	}
+
+	// Modules replaced with a local directory don't have a version
+	if module.Replace != nil {
+		module = module.Replace
+	}
+	if module.Version == "" || module.Version == "(devel)" {
+		return "devel"
+	}
+	return module.Version
+}
+
+func userAgent() string {
+	return "replicate-go/" + strings.TrimPrefix(Version(), "v")
+}
@@ -2,6 +2,9 @@ package replicate
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -9,8 +12,14 @@ const (
 	defaultPollingInterval = 1 * time.Second
 )
 
+var (
+	ErrWaitTimeout = errors.New("timed out waiting for prediction to finish")
+)
+
 type waitOptions struct {
-	interval time.Duration
+	interval    time.Duration
+	maxAttempts int
+	timeout     time.Duration
 }
 
 // WaitOption is a function that modifies an options struct.
@@ -24,11 +33,52 @@ func WithPollingInterval(interval time.Duration) WaitOption {
 	}
 }
 
+// WithMaxAttempts sets the maximum number of polling attempts.
+// A value of zero means there is no limit.
+func WithMaxAttempts(maxAttempts int) WaitOption {
+	return func(o *waitOptions) error {
+		if maxAttempts < 0 {
+			return fmt.Errorf("max attempts must not be negative: %d", maxAttempts)
+		}
+		o.maxAttempts = maxAttempts
+		return nil
+	}
+}
+
+// WithTimeout sets the maximum amount of time to wait, independent of the context.
+// A value of zero means there is no limit.
+func WithTimeout(timeout time.Duration) WaitOption {
+	return func(o *waitOptions) error {
+		if timeout < 0 {
+			return fmt.Errorf("timeout must not be negative: %s", timeout)
+		}
+		o.timeout = timeout
+		return nil
+	}
+}
+
+func newWaitOptions(opts []WaitOption) (*waitOptions, error) {
+	options := &waitOptions{
+		interval: defaultPollingInterval,
+	}
+
+	for _, option := range opts {
+		err := option(options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
 // Wait for a prediction to finish.
 //
 // This function blocks until the prediction has finished, or the context is canceled.
 // If the prediction has already finished, the function returns immediately.
 // If polling interval is less than or equal to zero, an error is returned.
+// If the maximum number of attempts or the timeout is exceeded,
+// an error wrapping ErrWaitTimeout is returned.
 func (r *Client) Wait(ctx context.Context, prediction *Prediction, opts ...WaitOption) error {
 	predChan, errChan := r.WaitAsync(ctx, prediction, opts...)
 
@@ -48,37 +98,177 @@ func (r *Client) Wait(ctx context.Context, prediction *Prediction, opts ...WaitO
 // If the prediction has already finished, the channel is closed immediately.
 // If polling interval is less than or equal to zero,
 // an error is sent to the error channel.
+// If the maximum number of attempts or the timeout is exceeded,
+// an error wrapping ErrWaitTimeout is sent to the error channel.
 func (r *Client) WaitAsync(ctx context.Context, prediction *Prediction, opts ...WaitOption) (<-chan *Prediction, <-chan error) {
+	return r.waitAsync(ctx, "prediction", prediction, r.GetPrediction, opts...)
+}
+
+// WaitAsyncStream is like WaitAsync, but for predictions created with streaming enabled,
+// it watches the prediction's stream for the done event instead of polling,
+// then gets the prediction once.
+// This makes far fewer requests than polling for predictions that run for a while.
+//
+// If the prediction doesn't have a stream URL, or the stream ends before the prediction has finished,
+// it falls back to polling like WaitAsync.
+// The timeout set with WithTimeout covers both the stream and polling.
+func (r *Client) WaitAsyncStream(ctx context.Context, prediction *Prediction, opts ...WaitOption) (<-chan *Prediction, <-chan error) {
+	if _, ok := prediction.StreamURL(); !ok {
+		return r.WaitAsync(ctx, prediction, opts...)
+	}
+
 	predChan := make(chan *Prediction)
 	errChan := make(chan error)
 
-	options := &waitOptions{
-		interval: defaultPollingInterval,
+	options, err := newWaitOptions(opts)
+	if err != nil {
+		go func() {
+			defer close(predChan)
+			defer close(errChan)
+			errChan <- err
+		}()
+		return predChan, errChan
 	}
 
-	for _, option := range opts {
-		err := option(options)
+	go func() {
+		defer close(predChan)
+		defer close(errChan)
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		var deadline time.Time
+		if options.timeout > 0 {
+			deadline = time.Now().Add(options.timeout)
+			streamCtx, cancel = context.WithDeadline(ctx, deadline)
+		}
+		defer cancel()
+
+		timedOut := func() error {
+			return fmt.Errorf("%w: prediction %s is still %s after %s", ErrWaitTimeout, prediction.ID, prediction.Status, options.timeout)
+		}
+
+		// Errors from the stream aren't returned, since polling can take over
+		sseChan, sseErrChan := r.StreamPredictionEvents(streamCtx, prediction)
+		for range sseChan { //nolint:all
+			// Wait for the done event
+		}
+		for range sseErrChan { //nolint:all
+		}
+
+		if err := ctx.Err(); err != nil {
+			errChan <- err
+			return
+		}
+		if streamCtx.Err() != nil {
+			errChan <- timedOut()
+			return
+		}
+
+		updatedPrediction, err := r.GetPrediction(ctx, prediction.ID)
 		if err != nil {
 			errChan <- err
-			close(predChan)
-			close(errChan)
-			return predChan, errChan
+			return
+		}
+
+		*prediction = *updatedPrediction
+		predChan <- updatedPrediction
+
+		if prediction.Status.Terminated() {
+			errChan <- nil
+			return
+		}
+
+		// The stream ended early, so poll for the rest of the timeout.
+		// Capping the caller's options makes append copy them
+		// instead of writing to the backing array the caller still owns.
+		pollOpts := opts[:len(opts):len(opts)]
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				errChan <- timedOut()
+				return
+			}
+			pollOpts = append(pollOpts, WithTimeout(remaining))
+		}
+
+		pollChan, pollErrChan := r.WaitAsync(ctx, prediction, pollOpts...)
+		for {
+			select {
+			case p, ok := <-pollChan:
+				if !ok {
+					pollChan = nil
+					continue
+				}
+				predChan <- p
+			case err := <-pollErrChan:
+				errChan <- err
+				return
+			}
 		}
+	}()
+
+	return predChan, errChan
+}
+
+// WaitForTraining waits for a training to finish.
+//
+// It behaves like Wait, polling the training until it has finished,
+// and updates the training in place.
+func (r *Client) WaitForTraining(ctx context.Context, training *Training, opts ...WaitOption) error {
+	predChan, errChan := r.waitAsync(ctx, "training", (*Prediction)(training), r.getTrainingAsPrediction, opts...)
+
+	go func() {
+		for range predChan { //nolint:all
+			// Drain the channel
+		}
+	}()
+
+	return <-errChan
+}
+
+// getTrainingAsPrediction gets a training as a Prediction, so it can be polled by waitAsync.
+func (r *Client) getTrainingAsPrediction(ctx context.Context, id string) (*Prediction, error) {
+	training, err := r.GetTraining(ctx, id)
+	return (*Prediction)(training), err
+}
+
+// waitAsync polls a prediction or training with get until it has finished.
+// kind names what's being waited for in errors.
+func (r *Client) waitAsync(ctx context.Context, kind string, prediction *Prediction, get func(context.Context, string) (*Prediction, error), opts ...WaitOption) (<-chan *Prediction, <-chan error) {
+	predChan := make(chan *Prediction)
+	errChan := make(chan error)
+
+	options, err := newWaitOptions(opts)
+	if err != nil {
+		go func() {
+			defer close(predChan)
+			defer close(errChan)
+			errChan <- err
+		}()
+		return predChan, errChan
 	}
 
+	ctx, stop := r.waitContext(ctx)
 	go func() {
 		defer close(predChan)
 		defer close(errChan)
+		defer stop()
 
 		ticker := time.NewTicker(options.interval)
 		defer ticker.Stop()
 
+		var timeout <-chan time.Time
+		if options.timeout > 0 {
+			timer := time.NewTimer(options.timeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
 		id := prediction.ID
 		attempts := 0
 		for {
 			select {
 			case <-ticker.C:
-				updatedPrediction, err := r.GetPrediction(ctx, id)
+				updatedPrediction, err := get(ctx, id)
 				if err != nil {
 					errChan <- err
 					return
@@ -93,6 +283,13 @@ func (r *Client) WaitAsync(ctx context.Context, prediction *Prediction, opts ...
 				}
 
 				attempts++
+				if options.maxAttempts > 0 && attempts >= options.maxAttempts {
+					errChan <- fmt.Errorf("%w: %s %s is still %s after %d attempts", ErrWaitTimeout, kind, id, prediction.Status, attempts)
+					return
+				}
+			case <-timeout:
+				errChan <- fmt.Errorf("%w: %s %s is still %s after %s", ErrWaitTimeout, kind, id, prediction.Status, options.timeout)
+				return
 			case <-ctx.Done():
 				errChan <- ctx.Err()
 				return
@@ -102,3 +299,94 @@ func (r *Client) WaitAsync(ctx context.Context, prediction *Prediction, opts ...
 
 	return predChan, errChan
 }
+
+// StreamPredictionLogs polls a prediction and sends each log line as it's appended.
+//
+// It accepts the same options as WaitAsync to configure polling.
+// A trailing line without a newline is sent once the prediction has finished.
+// Both channels are closed when the prediction has finished,
+// or the context is canceled.
+func (r *Client) StreamPredictionLogs(ctx context.Context, id string, opts ...WaitOption) (<-chan string, <-chan error) {
+	return r.streamLogs(ctx, "prediction", id, r.GetPrediction, opts...)
+}
+
+// StreamTrainingLogs polls a training and sends each log line as it's appended,
+// like StreamPredictionLogs.
+func (r *Client) StreamTrainingLogs(ctx context.Context, id string, opts ...WaitOption) (<-chan string, <-chan error) {
+	return r.streamLogs(ctx, "training", id, r.getTrainingAsPrediction, opts...)
+}
+
+// streamLogs polls a prediction or training with get, and sends each log line as it's appended.
+func (r *Client) streamLogs(ctx context.Context, kind string, id string, get func(context.Context, string) (*Prediction, error), opts ...WaitOption) (<-chan string, <-chan error) {
+	logChan := make(chan string)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(logChan)
+		defer close(errChan)
+
+		prediction := &Prediction{ID: id}
+		predChan, waitErrChan := r.waitAsync(ctx, kind, prediction, get, opts...)
+		defer func() {
+			// Drain the channels so that WaitAsync can finish
+			go func() {
+				for range predChan { //nolint:all
+				}
+			}()
+			for range waitErrChan { //nolint:all
+			}
+		}()
+
+		// seen holds the logs that have already been sent
+		seen := ""
+		send := func(line string) bool {
+			select {
+			case logChan <- line:
+				return true
+			case <-ctx.Done():
+				errChan <- ctx.Err()
+				return false
+			}
+		}
+
+		for {
+			select {
+			case p := <-predChan:
+				if p == nil || p.Logs == nil {
+					continue
+				}
+
+				logs := *p.Logs
+				if !strings.HasPrefix(logs, seen) {
+					// Logs were replaced rather than appended to, so start over
+					seen = ""
+				}
+
+				for {
+					i := strings.IndexByte(logs[len(seen):], '\n')
+					if i < 0 {
+						break
+					}
+					if !send(logs[len(seen) : len(seen)+i]) {
+						return
+					}
+					seen = logs[:len(seen)+i+1]
+				}
+
+				if p.Status.Terminated() && len(logs) > len(seen) {
+					if !send(logs[len(seen):]) {
+						return
+					}
+					seen = logs
+				}
+			case err := <-waitErrChan:
+				if err != nil {
+					errChan <- err
+				}
+				return
+			}
+		}
+	}()
+
+	return logChan, errChan
+}
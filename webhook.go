@@ -7,10 +7,32 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrUnknownWebhookEvent     = errors.New("unknown webhook event type")
+
+	// ErrWebhookMissingHeaders is returned when a webhook request is missing
+	// the headers needed to validate it, or they're malformed.
+	// The request wasn't sent by Replicate, or was altered.
+	ErrWebhookMissingHeaders = errors.New("missing or malformed webhook headers")
+
+	// ErrWebhookInvalidSecret is returned when a webhook signing secret isn't in the expected format.
+	// This is a problem with the receiver's configuration, not with the request.
+	ErrWebhookInvalidSecret = errors.New("invalid webhook signing secret")
+
+	// ErrWebhookTimestampOutOfTolerance is returned when a webhook request's timestamp
+	// is outside the tolerance given to ValidateWebhookRequestWithTolerance.
+	ErrWebhookTimestampOutOfTolerance = errors.New("webhook timestamp outside of tolerance")
 )
 
 type Webhook struct {
@@ -34,6 +56,63 @@ var WebhookEventAll = []WebhookEventType{
 	WebhookEventCompleted,
 }
 
+// NewWebhook returns a webhook that sends the given events to a URL.
+// Duplicate events are removed, and an unknown event returns an error
+// wrapping ErrUnknownWebhookEvent.
+// If no events are given, the API sends all events.
+func NewWebhook(url string, events ...WebhookEventType) (*Webhook, error) {
+	if url == "" {
+		return nil, errors.New("webhook URL must not be empty")
+	}
+
+	webhook := &Webhook{URL: url}
+	for _, event := range events {
+		if !slices.Contains(webhook.Events, event) {
+			webhook.Events = append(webhook.Events, event)
+		}
+	}
+
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// Validate returns an error wrapping ErrUnknownWebhookEvent
+// if the webhook has an event type that isn't in WebhookEventAll.
+// Webhooks are validated before predictions and trainings are created.
+func (w *Webhook) Validate() error {
+	for _, event := range w.Events {
+		if !slices.Contains(WebhookEventAll, event) {
+			return fmt.Errorf("%w: %q, must be one of %v", ErrUnknownWebhookEvent, event, WebhookEventAll)
+		}
+	}
+	return nil
+}
+
+// addToRequestBody sets the webhook fields of a request body.
+// Predictions, deployment predictions, and trainings all accept the same fields,
+// so they share this function to serialize webhooks the same way.
+func (w *Webhook) addToRequestBody(data map[string]interface{}) {
+	if w == nil {
+		return
+	}
+
+	data["webhook"] = w.URL
+	if len(w.Events) > 0 {
+		data["webhook_events_filter"] = w.Events
+	}
+}
+
+// webhookOrDefault returns the given webhook, or the client's default webhook if it's nil.
+func (r *Client) webhookOrDefault(webhook *Webhook) *Webhook {
+	if webhook != nil {
+		return webhook
+	}
+	return r.options.defaultWebhook
+}
+
 func (w WebhookEventType) String() string {
 	return string(w)
 }
@@ -57,6 +136,16 @@ func (wss *WebhookSigningSecret) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, alias)
 }
 
+var _ json.Marshaler = WebhookSigningSecret{}
+
+func (wss WebhookSigningSecret) MarshalJSON() ([]byte, error) {
+	if len(wss.rawJSON) > 0 {
+		return wss.rawJSON, nil
+	}
+	type Alias WebhookSigningSecret
+	return json.Marshal(Alias(wss))
+}
+
 // GetDefaultWebhookSecret gets the default webhook signing secret
 func (r *Client) GetDefaultWebhookSecret(ctx context.Context) (*WebhookSigningSecret, error) {
 	secret := &WebhookSigningSecret{}
@@ -68,13 +157,73 @@ func (r *Client) GetDefaultWebhookSecret(ctx context.Context) (*WebhookSigningSe
 	return secret, nil
 }
 
-// ValidateWebhookRequest validates the signature from an incoming webhook request using the provided secret
+// ValidateWebhookRequest validates the signature from an incoming webhook request using the provided secret.
+//
+// It returns false and no error if the signature doesn't match.
+// If the request's webhook headers are missing or malformed, it returns ErrWebhookMissingHeaders,
+// and if the secret isn't in the expected format, it returns ErrWebhookInvalidSecret.
 func ValidateWebhookRequest(req *http.Request, secret WebhookSigningSecret) (bool, error) {
+	return ValidateWebhookRequestWithSecrets(req, secret)
+}
+
+// ValidateWebhookRequestWithSecrets validates the signature from an incoming webhook request,
+// and returns true if it was signed with any of the provided secrets.
+// This allows validating against both the old and new secrets while a secret is being rotated.
+func ValidateWebhookRequestWithSecrets(req *http.Request, secrets ...WebhookSigningSecret) (bool, error) {
+	return validateWebhookRequest(req, 0, secrets...)
+}
+
+// ValidateWebhookRequestWithTolerance validates an incoming webhook request like ValidateWebhookRequest,
+// and also checks that its timestamp is within tolerance of the current time,
+// as recommended by Svix.
+// If it isn't, ErrWebhookTimestampOutOfTolerance is returned.
+//
+// ValidateWebhookRequest doesn't check the timestamp, so an intercepted request
+// can be replayed to it at any time. Checking the timestamp limits replays to the tolerance window.
+// A tolerance of 5 minutes allows for clock skew and retries.
+func ValidateWebhookRequestWithTolerance(req *http.Request, secret WebhookSigningSecret, tolerance time.Duration) (bool, error) {
+	if tolerance <= 0 {
+		return false, fmt.Errorf("webhook timestamp tolerance must be positive: %s", tolerance)
+	}
+	return validateWebhookRequest(req, tolerance, secret)
+}
+
+// validateWebhookRequest validates the signature of a webhook request against the secrets,
+// and if tolerance is positive, checks that the request's timestamp is within it.
+func validateWebhookRequest(req *http.Request, tolerance time.Duration, secrets ...WebhookSigningSecret) (bool, error) {
+	if len(secrets) == 0 {
+		return false, fmt.Errorf("%w: no webhook signing secrets provided", ErrWebhookInvalidSecret)
+	}
+
+	keys := make([][]byte, 0, len(secrets))
+	for _, secret := range secrets {
+		keyParts := strings.Split(secret.Key, "_")
+		if len(keyParts) != 2 {
+			return false, fmt.Errorf("%w: key isn't in the format whsec_<key>", ErrWebhookInvalidSecret)
+		}
+		key, err := base64.StdEncoding.DecodeString(keyParts[1])
+		if err != nil {
+			return false, fmt.Errorf("%w: failed to base64 decode secret key: %w", ErrWebhookInvalidSecret, err)
+		}
+		keys = append(keys, key)
+	}
+
 	id := req.Header.Get("webhook-id")
 	timestamp := req.Header.Get("webhook-timestamp")
 	signature := req.Header.Get("webhook-signature")
 	if id == "" || timestamp == "" || signature == "" {
-		return false, fmt.Errorf("missing required webhook headers: id=%s, timestamp=%s, signature=%s", id, timestamp, signature)
+		return false, fmt.Errorf("%w: id=%s, timestamp=%s, signature=%s", ErrWebhookMissingHeaders, id, timestamp, signature)
+	}
+
+	if tolerance > 0 {
+		seconds, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("%w: invalid timestamp: %s", ErrWebhookMissingHeaders, timestamp)
+		}
+		age := time.Since(time.Unix(seconds, 0))
+		if age > tolerance || age < -tolerance {
+			return false, fmt.Errorf("%w: timestamp=%s", ErrWebhookTimestampOutOfTolerance, timestamp)
+		}
 	}
 
 	bodyBytes, err := io.ReadAll(req.Body)
@@ -88,34 +237,82 @@ func ValidateWebhookRequest(req *http.Request, secret WebhookSigningSecret) (boo
 
 	signedContent := fmt.Sprintf("%s.%s.%s", id, timestamp, body)
 
-	keyParts := strings.Split(secret.Key, "_")
-	if len(keyParts) != 2 {
-		return false, fmt.Errorf("invalid secret key format: %s", secret.Key)
-	}
-	secretBytes, err := base64.StdEncoding.DecodeString(keyParts[1])
-	if err != nil {
-		return false, fmt.Errorf("failed to base64 decode secret key: %w", err)
-	}
-
-	h := hmac.New(sha256.New, secretBytes)
-	h.Write([]byte(signedContent))
-	computedSignatureBytes := h.Sum(nil)
-
+	var signatures [][]byte
 	for _, sig := range strings.Split(signature, " ") {
 		sigParts := strings.Split(sig, ",")
 		if len(sigParts) < 2 {
-			return false, fmt.Errorf("invalid signature format: %s", sig)
+			return false, fmt.Errorf("%w: invalid signature format: %s", ErrWebhookMissingHeaders, sig)
 		}
 
 		sigBytes, err := base64.StdEncoding.DecodeString(sigParts[1])
 		if err != nil {
-			return false, fmt.Errorf("failed to base64 decode signature: %w", err)
+			return false, fmt.Errorf("%w: failed to base64 decode signature: %w", ErrWebhookMissingHeaders, err)
 		}
+		signatures = append(signatures, sigBytes)
+	}
 
-		if hmac.Equal(sigBytes, computedSignatureBytes) {
-			return true, nil
+	for _, key := range keys {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(signedContent))
+		computedSignatureBytes := h.Sum(nil)
+
+		for _, sigBytes := range signatures {
+			if hmac.Equal(sigBytes, computedSignatureBytes) {
+				return true, nil
+			}
 		}
 	}
 
 	return false, nil
 }
+
+// WebhookEvent is the payload of a webhook request.
+// Exactly one of Prediction or Training is set.
+type WebhookEvent struct {
+	Prediction *Prediction
+	Training   *Training
+}
+
+// ParseWebhookEvent validates an incoming webhook request using the provided secret
+// and decodes its body into a prediction or training.
+//
+// The request body remains available to the caller afterwards.
+// If the signature doesn't match, ErrInvalidWebhookSignature is returned.
+func ParseWebhookEvent(req *http.Request, secret WebhookSigningSecret) (*WebhookEvent, error) {
+	isValid, err := ValidateWebhookRequest(req, secret)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	bodyBytes, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+
+	var shape struct {
+		Destination *string           `json:"destination"`
+		URLs        map[string]string `json:"urls"`
+	}
+	if err := json.Unmarshal(bodyBytes, &shape); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook payload: %w", err)
+	}
+
+	if shape.Destination != nil || strings.Contains(shape.URLs["get"], "/trainings/") {
+		training := &Training{}
+		if err := json.Unmarshal(bodyBytes, (*Prediction)(training)); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal training: %w", err)
+		}
+		return &WebhookEvent{Training: training}, nil
+	}
+
+	prediction := &Prediction{}
+	if err := json.Unmarshal(bodyBytes, prediction); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal prediction: %w", err)
+	}
+	return &WebhookEvent{Prediction: prediction}, nil
+}